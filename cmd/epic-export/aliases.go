@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// aliases are the Epic product slugs or links of games by normalized name, fixing recurring
+// mismatches.
+var aliases map[string]string
+
+// loadAliases reads the YAML map of game names to Epic slugs or links.
+func loadAliases(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var m map[string]string
+	if err = yaml.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	aliases = make(map[string]string, len(m))
+	for name, to := range m {
+		aliases[normName(name)] = strings.TrimSpace(to)
+	}
+	return nil
+}
+
+// aliasLink returns the link of the game by the aliases, if any.
+func aliasLink(name string) (string, bool) {
+	to, ok := aliases[normName(name)]
+	if !ok || len(to) == 0 {
+		return "", false
+	}
+	if strings.HasPrefix(to, "http://") || strings.HasPrefix(to, "https://") {
+		return to, true
+	}
+	return matcher.ProductLink(to), true
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// tocStart starts the table of contents of the HTML page, written at the end of the page so it
+	// lists all the cards so far, and shown at the top by the style of the page.
+	tocStart    = `<nav id="toc">`
+	tocFmt      = tocStart + `<details><summary>%s</summary>%s</details></nav>` + "\n"
+	tocEntryFmt = `<a href="#%s">%s</a> `
+)
+
+// tocID finds the anchors of the entries of a table of contents.
+var tocID = regexp.MustCompile(`<a href="#([^"]+)">`)
+
+// slugify returns the lowercase letters and digits of the name, with single dashes between the
+// words, like hades-ii for "Hades II", or game for a name without any.
+func slugify(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = sb.Len() > 0
+			continue
+		}
+		if dash {
+			sb.WriteByte('-')
+			dash = false
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "game"
+	}
+	return sb.String()
+}
+
+// anchors are the ids of the cards of the HTML page, and the entries of its table of contents.
+type anchors struct {
+	used map[string]bool
+	toc  strings.Builder
+	// kept are the entries of the merged page, written before the new ones.
+	kept string
+}
+
+// reset forgets the cards written since the merged ones, for writing them again.
+func (a *anchors) reset() {
+	a.used = map[string]bool{"toc": true}
+	for _, m := range tocID.FindAllStringSubmatch(a.kept, -1) {
+		a.used[m[1]] = true
+	}
+	a.toc.Reset()
+	a.toc.WriteString(a.kept)
+}
+
+// add returns the id of the card of the name, the slug of the name with a number from 2 for the
+// repeated ones, and lists it in the table of contents.
+func (a *anchors) add(name string) string {
+	if a.used == nil {
+		a.reset()
+	}
+	base := slugify(name)
+	id := base
+	for i := 2; a.used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	a.used[id] = true
+	fmt.Fprintf(&a.toc, tocEntryFmt, id, html.EscapeString(name))
+	return id
+}
+
+// html returns the table of contents, empty without any cards.
+func (a *anchors) html() string {
+	if a.toc.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf(tocFmt, html.EscapeString(text.contents), a.toc.String())
+}
+
+// cutTOC returns the page without its table of contents, and the entries of it.
+func cutTOC(page []byte) ([]byte, string) {
+	start := strings.LastIndex(string(page), tocStart)
+	if start < 0 {
+		return page, ""
+	}
+	toc, _, _ := strings.Cut(string(page[start:]), "</details></nav>")
+	_, entries, _ := strings.Cut(toc, "</summary>")
+	return page[:start], entries
+}
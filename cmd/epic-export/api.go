@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// serveAPICmd is the subcommand resolving the names posted to its HTTP API, for other services
+// asking for Epic links on demand.
+const serveAPICmd = "serve-api"
+
+const (
+	// apiAddr is the default address of serve-api.
+	apiAddr = ":8090"
+	// apiCandidates is the number of search results returned for a name without a sure match.
+	apiCandidates = 5
+	// apiMaxNames is the maximum number of names of a job, apiMaxBody of its request.
+	apiMaxNames = 500
+	apiMaxBody  = 1 << 20
+	// apiKeep is how long the finished jobs are kept for their status.
+	apiKeep = time.Hour
+)
+
+// Statuses of the API jobs.
+const (
+	jobQueued   = "queued"
+	jobRunning  = "running"
+	jobDone     = "done"
+	jobCanceled = "canceled"
+)
+
+var (
+	// apiQueue is the maximum number of jobs waiting, by -api-queue.
+	apiQueue = 100
+	// apiRate is the number of jobs a client can post in a minute, by -api-rate, 0 doesn't limit it.
+	apiRate = 30
+)
+
+// apiJob is the resolving of the names of a request, run by the workers in order.
+type apiJob struct {
+	ID       string      `json:"id"`
+	Status   string      `json:"status"`
+	Created  time.Time   `json:"created"`
+	Finished *time.Time  `json:"finished,omitempty"`
+	Results  []apiResult `json:"results"`
+	names    []string
+}
+
+// apiResult is the outcome of a name of a job. A name without a sure match has the best search
+// results as its candidates.
+type apiResult struct {
+	Name       string         `json:"name"`
+	Link       string         `json:"link,omitempty"`
+	Confidence int            `json:"confidence,omitempty"`
+	Method     string         `json:"method,omitempty"`
+	Candidates []apiCandidate `json:"candidates,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+type apiCandidate struct {
+	Name       string `json:"name"`
+	Link       string `json:"link"`
+	Confidence int    `json:"confidence"`
+}
+
+// apiServer queues the posted jobs for the workers, and keeps them for their status.
+type apiServer struct {
+	mu    sync.Mutex
+	jobs  map[string]*apiJob
+	queue chan *apiJob
+	limit *rateLimiter
+	// stopped is true once the workers stopped, so no more jobs are queued.
+	stopped bool
+}
+
+// serveAPI serves the API on the address with concurrency workers, until the context is done.
+func serveAPI(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s := &apiServer{jobs: map[string]*apiJob{}, queue: make(chan *apiJob, apiQueue), limit: newRateLimiter(apiRate)}
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /resolve", s.resolve)
+	mux.HandleFunc("GET /status/{id}", s.status)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutCtx)
+	}()
+	slog.Info("API is running", "url", "http://"+ln.Addr().String(), "workers", concurrency)
+	if err = srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	wg.Wait()
+	return nil
+}
+
+// resolve queues the job of the posted name or names, like {"name": "Hades"} or
+// {"names": ["Hades", "Celeste"]}, and answers its ID.
+func (s *apiServer) resolve(w http.ResponseWriter, r *http.Request) {
+	if !s.limit.allow(clientOf(r)) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.limit.every.Seconds())+1))
+		apiError(w, http.StatusTooManyRequests, "too many requests, try again later")
+		return
+	}
+	var req struct {
+		Name  string   `json:"name"`
+		Names []string `json:"names"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBody)).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	var names []string
+	for _, n := range append(req.Names, req.Name) {
+		if n = strings.TrimSpace(n); len(n) > 0 {
+			names = append(names, n)
+		}
+	}
+	switch {
+	case len(names) == 0:
+		apiError(w, http.StatusBadRequest, "no name to resolve")
+		return
+	case len(names) > apiMaxNames:
+		apiError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("at most %d names of a request", apiMaxNames))
+		return
+	}
+	job := &apiJob{ID: rand.Text(), Status: jobQueued, Created: time.Now().UTC(), names: names,
+		Results: make([]apiResult, 0, len(names))}
+	s.mu.Lock()
+	s.expire()
+	if s.stopped {
+		s.mu.Unlock()
+		apiError(w, http.StatusServiceUnavailable, "the API is shutting down")
+		return
+	}
+	select {
+	case s.queue <- job:
+		s.jobs[job.ID] = job
+	default:
+		s.mu.Unlock()
+		apiError(w, http.StatusServiceUnavailable, "the queue is full, try again later")
+		return
+	}
+	b, _ := json.Marshal(job)
+	s.mu.Unlock()
+	slog.Debug("API job queued", "job", job.ID, "names", len(names))
+	w.Header().Set("Location", "/status/"+job.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(b)
+}
+
+// status answers the job by its ID, with the results of its names resolved so far.
+func (s *apiServer) status(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	var b []byte
+	if ok {
+		b, _ = json.Marshal(job)
+	}
+	s.mu.Unlock()
+	if !ok {
+		apiError(w, http.StatusNotFound, "unknown job")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// expire forgets the jobs finished longer than apiKeep ago, under the lock.
+func (s *apiServer) expire() {
+	for id, job := range s.jobs {
+		if job.Finished != nil && time.Since(*job.Finished) > apiKeep {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// work resolves the names of the queued jobs until the context is done, the jobs left are
+// canceled.
+func (s *apiServer) work(ctx context.Context) {
+	for {
+		var job *apiJob
+		select {
+		case job = <-s.queue:
+		case <-ctx.Done():
+			s.cancelQueued()
+			return
+		}
+		s.mu.Lock()
+		job.Status = jobRunning
+		s.mu.Unlock()
+		for _, name := range job.names {
+			if ctx.Err() != nil {
+				break
+			}
+			res := apiResolve(ctx, name)
+			s.mu.Lock()
+			job.Results = append(job.Results, res)
+			s.mu.Unlock()
+		}
+		now := time.Now().UTC()
+		s.mu.Lock()
+		job.Status, job.Finished = jobDone, &now
+		if ctx.Err() != nil {
+			job.Status = jobCanceled
+		}
+		s.mu.Unlock()
+	}
+}
+
+// cancelQueued cancels the jobs left in the queue after the workers stopped.
+func (s *apiServer) cancelQueued() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	now := time.Now().UTC()
+	for {
+		select {
+		case job := <-s.queue:
+			job.Status, job.Finished = jobCanceled, &now
+		default:
+			return
+		}
+	}
+}
+
+// apiResolve finds the link of the name without asking, like a run does before its prompts: by
+// the aliases, the match database, the imported mappings, the naive slug, and the exact name or
+// the -auto-accept-threshold of the search results.
+func apiResolve(ctx context.Context, name string) apiResult {
+	res := apiResult{Name: name}
+	g := &game{Name: name}
+	g.stage("api")
+	sure := func(method, link string, conf int) apiResult {
+		res.Method, res.Confidence = method, conf
+		res.Link = canonicalLink(&result{Link: link})
+		return res
+	}
+	if link, ok := aliasLink(name); ok {
+		return sure(methodAlias, link, 100)
+	}
+	if r, ok := dbGet(g.dbKey()); ok && r.Method != methodSkip && len(r.Link) > 0 {
+		countStored()
+		return sure(r.Method, r.Link, r.Confidence)
+	}
+	if link, ok := mappingLink(name); ok {
+		return sure(methodMapping, link, 100)
+	}
+	if !inCatalog(name) {
+		link, _, err := matcher.ResolveVariant(ctx, name)
+		if err == nil || errors.Is(err, epicmatch.ErrRegionLocked) {
+			return apiStore(g, sure(methodSlug, link, 100))
+		}
+		g.log.Debug("no product page by name", "err", err)
+	}
+	g.stage("search")
+	matches, err := g.storeSearch(ctx, name)
+	for _, m := range matches {
+		if epicmatch.SameName(m.Name, name) {
+			return apiStore(g, sure(methodSearch, m.Link, 100))
+		}
+	}
+	if len(matches) > 0 && autoAccept > 0 && matches[0].Confidence >= autoAccept {
+		return apiStore(g, sure(methodAuto, matches[0].Link, matches[0].Confidence))
+	}
+	for _, m := range matches[:min(len(matches), apiCandidates)] {
+		res.Candidates = append(res.Candidates, apiCandidate{Name: m.Name, Link: canonicalLink(&result{Link: m.Link}),
+			Confidence: m.Confidence})
+	}
+	if err != nil {
+		noteUnavailable(err)
+		g.log.Warn("search failed", "err", err)
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// apiStore stores the found match in the match database, so later runs and requests skip it.
+func apiStore(g *game, res apiResult) apiResult {
+	if !dryRun {
+		dbPut(g.dbKey(), &result{Name: res.Name, Link: res.Link, Confidence: res.Confidence, Method: res.Method})
+	}
+	return res
+}
+
+// apiError answers the error as JSON, like {"error": "unknown job"}.
+func apiError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// clientOf returns the address of the client of the request, without its port.
+func clientOf(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter lets a client post a burst of jobs, then one in every period.
+type rateLimiter struct {
+	mu      sync.Mutex
+	every   time.Duration
+	burst   float64
+	clients map[string]*bucket
+}
+
+// bucket is the number of jobs the client can post now, as of last.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter limits the clients to perMinute jobs in a minute, nil for 0 not limiting them.
+func newRateLimiter(perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{every: time.Minute / time.Duration(perMinute), burst: float64(perMinute),
+		clients: map[string]*bucket{}}
+}
+
+// allow tells if the client can post a job now, and takes it from its bucket.
+func (l *rateLimiter) allow(client string) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: l.burst}
+		l.clients[client] = b
+	} else {
+		b.tokens = min(l.burst, b.tokens+float64(now.Sub(b.last))/float64(l.every))
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
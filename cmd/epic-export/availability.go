@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+var (
+	// services are the keys of the streaming and subscription services checked by -availability,
+	// like geforce-now.
+	services []string
+	// serviceGames are the stores of the games by service and normalized name, see addAvailability.
+	serviceGames = map[string]map[string][]string{}
+)
+
+// parseServices returns the keys of the comma separated services, checking them.
+func parseServices(list string) ([]string, error) {
+	var keys []string
+	for _, k := range strings.Split(list, ",") {
+		if k = strings.TrimSpace(k); len(k) == 0 || slices.Contains(keys, k) {
+			continue
+		}
+		if _, err := epicmatch.ServiceName(k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// loadServices gets the games of the services by their catalogs. A failing service is left out
+// with a warning, the others are still checked.
+func loadServices(ctx context.Context) {
+	for _, key := range slices.Clone(services) {
+		titles, err := matcher.ServiceTitles(ctx, key)
+		if err != nil {
+			slog.Warn("failed to load service, leaving it out", "service", key, "err", err)
+			services = slices.DeleteFunc(services, func(s string) bool { return s == key })
+			continue
+		}
+		games := make(map[string][]string, len(titles))
+		for _, t := range titles {
+			name := normName(t.Title)
+			games[name] = append(games[name], t.Stores...)
+		}
+		serviceGames[key] = games
+		slog.Debug("service loaded", "service", key, "games", len(games))
+	}
+}
+
+// addAvailability annotates the matched result with the services it's playable on. A streaming
+// service has to stream the copy of the store of the result, the Epic one by default.
+func addAvailability(r *result) {
+	if len(services) == 0 || len(r.Link) == 0 {
+		return
+	}
+	r.Available = nil
+	store := strings.ToLower(r.Store)
+	if len(store) == 0 {
+		store = "epic"
+	}
+	for _, key := range services {
+		stores, ok := serviceGames[key][normName(r.Name)]
+		if ok && (len(stores) == 0 || slices.ContainsFunc(stores, func(s string) bool {
+			return strings.Contains(strings.ToLower(s), store)
+		})) {
+			r.Available = append(r.Available, key)
+		}
+	}
+}
+
+// availabilityHTML formats the services of the result as badges.
+func availabilityHTML(keys []string) string {
+	var sb strings.Builder
+	for _, key := range keys {
+		name, _ := epicmatch.ServiceName(key)
+		fmt.Fprintf(&sb, `<span class="service %s">%s</span>`, key, html.EscapeString(name))
+	}
+	return sb.String()
+}
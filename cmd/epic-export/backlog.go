@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+const (
+	opmlHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0"><head><title>%s</title></head><body>
+`
+	opmlFooter = "</body></opml>\n"
+)
+
+// backlogTags returns the tags of the result for the note apps: backlog, the launchers, the store,
+// the services and the genres, like backlog, epic, game-pass and genre/action.
+func backlogTags(r *result) []string {
+	tags := []string{"backlog"}
+	add := func(prefix, name string) {
+		if t := prefix + slugify(name); len(name) > 0 && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	for _, s := range r.Sources {
+		add("", s)
+	}
+	if r.Notes != nil {
+		for _, t := range r.Notes.Tags {
+			add("", t)
+		}
+	}
+	if len(r.Store) > 0 {
+		add("", r.Store)
+	} else if len(r.Link) > 0 {
+		add("", "epic")
+	}
+	for _, s := range r.Available {
+		add("", s)
+	}
+	if r.Metadata != nil {
+		for _, g := range r.Metadata.Genres {
+			add("genre/", g)
+		}
+	}
+	return tags
+}
+
+// obsidianOutput writes a Markdown note of a heading per game with its logo, link and tags, like
+// Obsidian shows them.
+type obsidianOutput struct {
+	w *bufio.Writer
+}
+
+func (o *obsidianOutput) begin() {
+	fmt.Fprintf(o.w, "---\ntags: [games]\n---\n# %s\n", text.title)
+}
+
+func (o *obsidianOutput) write(r *result) {
+	fmt.Fprintf(o.w, "\n## %s\n", strings.ReplaceAll(r.Name, "\n", " "))
+	if len(r.Logo) > 0 {
+		fmt.Fprintf(o.w, "![logo](%s)\n", r.Logo)
+	}
+	if len(r.Link) > 0 {
+		store := r.Store
+		if len(store) == 0 {
+			store = "Epic Games Store"
+		}
+		fmt.Fprintf(o.w, "[%s](%s)\n", store, localLink(r.Link))
+	}
+	if len(r.Parts) > 0 {
+		fmt.Fprintln(o.w, partsMarkdown(r.Parts, " · "))
+	}
+	tags := backlogTags(r)
+	for i, t := range tags {
+		tags[i] = "#" + t
+	}
+	fmt.Fprintln(o.w, strings.Join(tags, " "))
+}
+
+func (o *obsidianOutput) end() {}
+
+// opmlOutput writes an OPML outline of a link per game, categorized by its tags.
+type opmlOutput struct {
+	w *bufio.Writer
+}
+
+// opmlOutline is a game in the OPML outline.
+type opmlOutline struct {
+	XMLName  xml.Name `xml:"outline"`
+	Text     string   `xml:"text,attr"`
+	Type     string   `xml:"type,attr,omitempty"`
+	URL      string   `xml:"url,attr,omitempty"`
+	Image    string   `xml:"image,attr,omitempty"`
+	Category string   `xml:"category,attr,omitempty"`
+	// Parts are the components of a collection game.
+	Parts []opmlOutline
+}
+
+func (o *opmlOutput) begin() {
+	var title strings.Builder
+	xml.EscapeText(&title, []byte(text.title))
+	fmt.Fprintf(o.w, opmlHeader, title.String())
+}
+
+func (o *opmlOutput) write(r *result) {
+	ol := opmlOutline{Text: r.Name, Image: r.Logo}
+	if len(r.Link) > 0 {
+		ol.Type, ol.URL = "link", localLink(r.Link)
+	}
+	tags := backlogTags(r)
+	for i, t := range tags {
+		tags[i] = "/" + t
+	}
+	ol.Category = strings.Join(tags, ",")
+	for _, p := range r.Parts {
+		part := opmlOutline{Text: p.Name}
+		if len(p.Link) > 0 {
+			part.Type, part.URL = "link", localLink(p.Link)
+		}
+		ol.Parts = append(ol.Parts, part)
+	}
+	b, err := xml.Marshal(ol)
+	if err != nil {
+		slog.Error("failed to marshal result", "game", r.Name, "stage", "output", "err", err)
+		return
+	}
+	o.w.Write(b)
+	o.w.WriteByte('\n')
+}
+
+func (o *opmlOutput) end() {
+	o.w.WriteString(opmlFooter)
+}
+
+// obsidianNames returns the names of the game headings.
+func obsidianNames(r io.Reader) ([]string, error) {
+	var names []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if name, ok := strings.CutPrefix(sc.Text(), "## "); ok {
+			names = append(names, name)
+		}
+	}
+	return names, sc.Err()
+}
+
+// opmlNames returns the text of the outlines.
+func opmlNames(r io.Reader) ([]string, error) {
+	var doc struct {
+		Outlines []opmlOutline `xml:"body>outline"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(doc.Outlines))
+	for i, ol := range doc.Outlines {
+		names[i] = ol.Text
+	}
+	return names, nil
+}
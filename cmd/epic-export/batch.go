@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// batchFiles are the results of the batch being resolved by -batch-size, each batch written to
+// its own file next to the output, so a huge input has usable results long before the end.
+type batchFiles struct {
+	// size is the number of games of a batch, 0 resolves them all at once without batch files.
+	size int
+	// path is the output path, order is the order of the results in the batch files, and
+	// newOutput returns an output of the format of the run.
+	path, order string
+	newOutput   func(w *bufio.Writer) (output, error)
+	mtx         sync.Mutex
+	results     []*result
+}
+
+var batches batchFiles
+
+// add keeps the result for the file of the batch.
+func (b *batchFiles) add(r *result) {
+	if b.size == 0 || b.newOutput == nil {
+		return
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.results = append(b.results, r)
+}
+
+// write writes the results of the batch into its own file, like games.batch-002.html for
+// games.html, and forgets them.
+func (b *batchFiles) write(n int) error {
+	b.mtx.Lock()
+	all := b.results
+	b.results = nil
+	b.mtx.Unlock()
+	if b.newOutput == nil {
+		return nil
+	}
+	ext := filepath.Ext(b.path)
+	path := fmt.Sprintf("%s.batch-%03d%s", strings.TrimSuffix(b.path, ext), n, ext)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	o, err := b.newOutput(w)
+	if err != nil {
+		return err
+	}
+	sortResults(b.order, all)
+	groupResults(all)
+	o.begin()
+	for _, r := range all {
+		o.write(r)
+	}
+	o.end()
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	slog.Info("batch written", "batch", n, "games", len(all), "path", path)
+	return f.Close()
+}
+
+// runBatches resolves the games by batches of -batch-size, writing the results of each batch
+// into its own file, or all at once without it. The retries and the decisions come after all
+// the batches, into the output only.
+func runBatches(ctx context.Context, games []*game, tokens chan *work) {
+	if batches.size == 0 {
+		runPass(ctx, games, tokens, uiProgress)
+		return
+	}
+	for start := 0; start < len(games) && ctx.Err() == nil; start += batches.size {
+		runPass(ctx, games[start:min(start+batches.size, len(games))], tokens, uiProgress)
+		if ctx.Err() != nil {
+			break // the batch is incomplete
+		}
+		if n := start/batches.size + 1; !dryRun {
+			if err := batches.write(n); err != nil {
+				slog.Error("failed to write batch", "batch", n, "err", err)
+			}
+		}
+	}
+}
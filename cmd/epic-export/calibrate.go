@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+	"gopkg.in/yaml.v3"
+)
+
+// calibrateCmd is the subcommand probing the store by increasing request rates, for the fastest
+// -concurrency and -delay without Cloudflare challenges from the IP.
+const calibrateCmd = "calibrate"
+
+// calibrateProbes is the number of store searches at each rate.
+const calibrateProbes = 20
+
+// rateLevel is a request rate of the calibration.
+type rateLevel struct {
+	concurrency int
+	delay       time.Duration
+}
+
+// calibrateLevels are the request rates probed, from the slowest one.
+var calibrateLevels = []rateLevel{
+	{1, 2 * time.Second}, {2, time.Second}, {3, 600 * time.Millisecond}, {5, 300 * time.Millisecond},
+	{5, 200 * time.Millisecond}, {8, 100 * time.Millisecond}, {10, 50 * time.Millisecond},
+}
+
+// calibrateNames are the searched names without names given to calibrate, popular games of
+// search results that change rarely.
+var calibrateNames = []string{
+	"Hades", "Celeste", "Control", "Alan Wake", "Fortnite", "Rocket League", "Death Stranding", "Subnautica",
+	"Hollow Knight", "Dead Cells", "Outer Wilds", "Disco Elysium", "Satisfactory", "Borderlands", "Cyberpunk",
+	"Red Dead Redemption", "Grand Theft Auto", "Fall Guys", "Genshin Impact", "Kingdom Come",
+}
+
+// calibrate probes the store by the levels of calibrateLevels, searching the names, until a
+// level meets a challenge, and writes the rate of the last level without any to the config
+// file, unless it's a dry run. The delay of the store requests is restored to delay after each
+// level.
+func calibrate(ctx context.Context, names []string, configPath string, delay time.Duration) error {
+	if len(names) == 0 {
+		names = calibrateNames
+	}
+	tw := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "concurrency\tdelay\tsearches\tchallenges\taverage latency")
+	var best *rateLevel
+	var bestLatency time.Duration
+	for i, level := range calibrateLevels {
+		slog.Info("probing the store", "concurrency", level.concurrency, "delay", level.delay)
+		searches, challenges, latency, err := probeRate(ctx, level, names, delay)
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\n", level.concurrency, level.delay, searches, challenges,
+			latency.Round(time.Millisecond))
+		if err != nil {
+			tw.Flush()
+			return err
+		}
+		if challenges > 0 {
+			break
+		}
+		best, bestLatency = &calibrateLevels[i], latency
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if best == nil {
+		return errors.New("the store challenges even the slowest rate, use -solver or -cf-clearance")
+	}
+	rate := rateLevel{concurrency: inferConcurrency(*best, bestLatency), delay: best.delay}
+	fmt.Printf("recommended: -concurrency %d -delay %s\n", rate.concurrency, rate.delay)
+	fmt.Println("the concurrency is inferred from the delay and the average latency, as the searches at the " +
+		"same time share the rate limit of the delay")
+	if dryRun {
+		return nil
+	}
+	if len(configPath) == 0 {
+		if configPath = defaultConfig(); len(configPath) == 0 {
+			return errors.New("no config directory, give -config")
+		}
+	}
+	if err := writeRate(configPath, rate); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", configPath, err)
+	}
+	fmt.Println("written to", configPath)
+	return nil
+}
+
+// inferConcurrency returns the searches at the same time keeping the store requests at the delay
+// of the level: the ones waiting for their responses at the latency, at least one and up to the
+// probed concurrency. The store requests share a single limiter of the delay, so more searches at
+// the same time would only wait for it.
+func inferConcurrency(level rateLevel, latency time.Duration) int {
+	n := int((latency + level.delay - 1) / level.delay)
+	return min(max(n, 1), level.concurrency)
+}
+
+// probeRate searches the store calibrateProbes times at the rate, stopping at the first
+// challenge, then restores the delay of the store requests to base. Returns the searches done,
+// the challenges met and their average latency.
+func probeRate(parent context.Context, level rateLevel, names []string, base time.Duration) (int, int, time.Duration, error) {
+	matcher.SetDelay(level.delay)
+	defer matcher.SetDelay(base)
+	before := matcher.Stats()
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	probes := make(chan string)
+	go func() {
+		defer close(probes)
+		for i := range calibrateProbes {
+			select {
+			case probes <- names[i%len(names)]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var mtx sync.Mutex
+	var searches int
+	var failed error
+	var wg sync.WaitGroup
+	for range level.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range probes {
+				_, err := matcher.Search(ctx, name)
+				if ctx.Err() != nil {
+					return
+				}
+				mtx.Lock()
+				if err != nil && !errors.Is(err, epicmatch.ErrNoResults) && failed == nil {
+					failed = fmt.Errorf("failed to search %s: %w", name, err)
+				}
+				searches++
+				stop := failed != nil
+				mtx.Unlock()
+				if stop || matcher.Stats().Challenges > before.Challenges {
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	after := matcher.Stats()
+	var latency time.Duration
+	if n := after.Requests - before.Requests; n > 0 {
+		latency = (after.Latency - before.Latency) / time.Duration(n)
+	}
+	if failed == nil {
+		failed = parent.Err()
+	}
+	return searches, after.Challenges - before.Challenges, latency, failed
+}
+
+// writeRate sets the concurrency and the delay of the YAML config file, keeping its other
+// options and comments. A missing file is created.
+func writeRate(path string, level rateLevel) error {
+	var doc yaml.Node
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err = yaml.Unmarshal(b, &doc); err != nil {
+			return err
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return errors.New("the config is not a map of options")
+	}
+	setOption(root, "concurrency", fmt.Sprint(level.concurrency))
+	setOption(root, "delay", level.delay.String())
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(f)
+	enc.SetIndent(2)
+	if err = errors.Join(enc.Encode(&doc), enc.Close()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// setOption sets the value of the key in the YAML map, adding it to the end if it's missing.
+func setOption(m *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			v.Kind, v.Tag, v.Style, v.Value, v.Content = yaml.ScalarNode, "", 0, value, nil
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value})
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// applyCmd is the subcommand writing the decisions of an edited candidates file, merging them
+// into the existing output.
+const applyCmd = "apply"
+
+// Picks of the candidates file besides a link.
+const (
+	pickNone = "none" // keep the game without a link
+	pickSkip = "skip" // skip the game, like in the picker
+)
+
+var (
+	// candidatesPath is the file of the fuzzy games with their search results by -candidates-out,
+	// written instead of asking the user. Empty without it.
+	candidatesPath string
+	candMtx        sync.Mutex
+	candGames      []candidateGame
+)
+
+// candidate is a search result of a fuzzy game.
+type candidate struct {
+	Name      string `json:"name"`
+	Link      string `json:"link"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	Score     int    `json:"score"`
+	// Store is the name of the fallback store of the link, empty for Epic.
+	Store string `json:"store,omitempty"`
+}
+
+// candidateGame is a game of the candidates file with its ranked search results. Pick is edited
+// for apply to one of the candidate links or another link, none or skip, the games without a pick
+// are left for review.
+type candidateGame struct {
+	game
+	Candidates []candidate `json:"candidates"`
+	Pick       string      `json:"pick"`
+	// Dup keeps the duplicate names apart in the match database, see game.dbKey.
+	Dup bool `json:"dup,omitempty"`
+}
+
+// addCandidates keeps the search results of the game for the candidates file.
+func addCandidates(g *game) {
+	cg := candidateGame{game: *g, Candidates: make([]candidate, 0, len(g.work.items)), Dup: g.dup}
+	for _, m := range g.work.items {
+		cg.Candidates = append(cg.Candidates, candidate{Name: m.Name, Link: m.Link, Thumbnail: m.Image,
+			Score: m.Confidence, Store: m.Store})
+	}
+	candMtx.Lock()
+	defer candMtx.Unlock()
+	candGames = append(candGames, cg)
+}
+
+// writeCandidates writes the fuzzy games in the input order with their search results, if any.
+func writeCandidates() error {
+	candMtx.Lock()
+	defer candMtx.Unlock()
+	if len(candGames) == 0 {
+		return nil
+	}
+	slices.SortFunc(candGames, func(a, b candidateGame) int { return a.index - b.index })
+	b, err := json.MarshalIndent(candGames, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(candidatesPath, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d games need your decision, set their pick and run: epic-export %s -o <output> %s\n",
+		len(candGames), applyCmd, candidatesPath)
+	return nil
+}
+
+// readCandidates reads the games of the edited candidates file, with their picks and search
+// results.
+func readCandidates(path string) ([]*game, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cgs []candidateGame
+	if err = json.NewDecoder(f).Decode(&cgs); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	games := make([]*game, 0, len(cgs))
+	for _, cg := range cgs {
+		g := &cg.game
+		g.picked, g.dup = cg.Pick, cg.Dup
+		g.work = &work{items: make([]epicmatch.Match, 0, len(cg.Candidates))}
+		for _, c := range cg.Candidates {
+			g.work.items = append(g.work.items, epicmatch.Match{Name: c.Name, Link: c.Link, Image: c.Thumbnail,
+				Confidence: c.Score, Store: c.Store})
+		}
+		games = append(games, g)
+	}
+	return games, nil
+}
+
+// apply writes the game by its pick from the candidates file. A typed link is checked like in the
+// picker, the games without a pick or an invalid link are left for review.
+func (g *game) apply(ctx context.Context) {
+	switch g.picked {
+	case "":
+		addPending(g)
+		return
+	case pickSkip:
+		countMethod(methodSkip, 1)
+		addFailure(g.Name, "pick", nil)
+		dbPut(g.dbKey(), &result{Name: g.Name, Method: methodSkip})
+		emitSkipped(g)
+		return
+	case pickNone:
+		g.write(ctx, methodNone, "", 0)
+		return
+	}
+	for _, m := range g.work.items {
+		switch {
+		case m.Link != g.picked:
+			continue
+		case len(g.Link) > 0 && m.Link == g.Link:
+			m.Confidence = 100
+			g.writeMatch(ctx, methodSource, &m)
+		case len(m.Name) > 0:
+			g.writeMatch(ctx, methodPick, &m)
+		default:
+			g.writeMatch(ctx, methodImage, &m)
+		}
+		return
+	}
+	link, _, err := matcher.CheckLink(ctx, g.picked)
+	if err != nil {
+		if ctx.Err() == nil {
+			g.log.Warn("picked link is invalid, left for review", "link", g.picked, "err", err)
+			addFailure(g.Name, "apply", err)
+			addPending(g)
+		}
+		return
+	}
+	g.write(ctx, methodTyped, link, 100)
+}
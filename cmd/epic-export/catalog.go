@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// useCatalog searches the title index of the store catalog before the store, by -catalog-index.
+var useCatalog bool
+
+// loadCatalog loads the title index of the store catalog, searching the store only if it fails.
+func loadCatalog(ctx context.Context) {
+	if err := matcher.LoadCatalog(ctx); err != nil {
+		slog.Warn("failed to load the store catalog, searching the store instead", "err", err)
+		useCatalog = false
+	}
+}
+
+// inCatalog tells if the catalog index has a title of the name, so it's found without requests.
+func inCatalog(name string) bool {
+	if !useCatalog {
+		return false
+	}
+	matches, err := matcher.SearchCatalog(name)
+	return err == nil && hasName(matches, name)
+}
+
+// storeSearch returns the similar titles of the catalog index for the name if it has the name, or
+// if it has any for the fuzzy search, and the results of the store search otherwise.
+func (g *game) storeSearch(ctx context.Context, name string) ([]epicmatch.Match, error) {
+	if useCatalog {
+		matches, err := matcher.SearchCatalog(name)
+		if err == nil && (g.isFuzzy || hasName(matches, name)) {
+			g.log.Debug("found in the catalog index", "name", name, "results", len(matches))
+			return matches, nil
+		}
+	}
+	return matcher.Search(ctx, name)
+}
+
+// hasName tells if one of the matches has the name.
+func hasName(matches []epicmatch.Match, name string) bool {
+	return slices.ContainsFunc(matches, func(m epicmatch.Match) bool { return epicmatch.SameName(m.Name, name) })
+}
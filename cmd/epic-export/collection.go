@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// part is a component of a collection game, like the season pass of "Borderlands 3 + Season
+// Pass", without a link if it wasn't found.
+type part struct {
+	Name string `json:"name"`
+	Link string `json:"link,omitempty"`
+}
+
+// searchCollection resolves the components of a collection name, like "Borderlands 3 + Season
+// Pass" or "BioShock: The Collection", instead of choosing from the search results of all of it.
+// Returns true if the game was written as a card of their links, linked to the first one found.
+func (g *game) searchCollection(ctx context.Context) bool {
+	if parts, franchise := epicmatch.SplitCollection(g.Name); g.dup || len(parts) == 0 && len(franchise) == 0 {
+		return false
+	}
+	g.stage("collection")
+	matches, err := matcher.ResolveCollection(ctx, g.Name)
+	if err != nil {
+		if errors.Is(err, epicmatch.ErrNoResults) {
+			g.log.Debug("no components of the collection", "err", err)
+		} else if ctx.Err() == nil {
+			g.log.Warn("failed to resolve the collection", "err", err)
+		}
+		return false
+	}
+	r := &result{Name: g.Name, Confidence: 100, Logo: g.Logo, Method: methodCollection}
+	for _, m := range matches {
+		p := part{Name: m.Name, Link: canonicalLink(&result{Link: m.Link})}
+		if len(r.Link) == 0 {
+			r.Link = m.Link
+		}
+		r.Parts = append(r.Parts, p)
+	}
+	g.log.Info("collection resolved by its components", "components", len(r.Parts))
+	g.save(ctx, r)
+	return true
+}
+
+// partsHTML formats the links of the components of a collection as a new line of the card, if
+// any.
+func partsHTML(parts []part) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	links := make([]string, len(parts))
+	for i, p := range parts {
+		if len(p.Link) == 0 {
+			links[i] = fmt.Sprintf(`<span>%s</span>`, html.EscapeString(p.Name))
+			continue
+		}
+		links[i] = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(localLink(p.Link)), html.EscapeString(p.Name))
+	}
+	return `<br/><span class="parts">` + strings.Join(links, " · ") + `</span>`
+}
+
+// partsMarkdown formats the links of the components of a collection for the Markdown outputs,
+// empty without any.
+func partsMarkdown(parts []part, sep string) string {
+	links := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if len(p.Link) == 0 {
+			links = append(links, p.Name)
+			continue
+		}
+		links = append(links, fmt.Sprintf("[%s](%s)", p.Name, localLink(p.Link)))
+	}
+	return strings.Join(links, sep)
+}
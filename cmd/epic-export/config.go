@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// headers is a repeatable flag of extra store request headers in "name: value" format.
+type headers map[string]string
+
+func (h headers) String() string {
+	pairs := make([]string, 0, len(h))
+	for name, value := range h {
+		pairs = append(pairs, name+": "+value)
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (h headers) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || len(strings.TrimSpace(name)) == 0 {
+		return fmt.Errorf("header %q should be in \"name: value\" format", s)
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	return nil
+}
+
+// paths is a repeatable flag of input paths.
+type paths []string
+
+func (p *paths) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *paths) Set(s string) error {
+	*p = append(*p, s)
+	return nil
+}
+
+// defaultConfig returns the path of the config file loaded without -config.
+func defaultConfig() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "epic-export", "config.yaml")
+}
+
+// loadConfig sets the flags from the YAML config file, keyed by the flag names. Flags given on
+// the command line stay as they are. Lists are joined by commas, or set one by one for the
+// header flag, which also accepts a map. A missing default config file is not an error.
+func loadConfig(path string) error {
+	explicit := len(path) > 0
+	if !explicit {
+		if path = defaultConfig(); len(path) == 0 {
+			return nil
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg map[string]any
+	if err = yaml.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, value := range cfg {
+		f := flag.Lookup(name)
+		if f == nil || name == "config" {
+			return fmt.Errorf("unknown config option %q in %s", name, path)
+		}
+		if set[name] {
+			continue
+		}
+		if err = setFlag(f, value); err != nil {
+			return fmt.Errorf("wrong config option %q in %s: %w", name, path, err)
+		}
+	}
+	return nil
+}
+
+// setFlag sets the flag to the YAML value.
+func setFlag(f *flag.Flag, value any) error {
+	_, isMap := f.Value.(headers)
+	_, isPaths := f.Value.(*paths)
+	many := isMap || isPaths
+	switch v := value.(type) {
+	case map[string]any:
+		if !isMap {
+			return errors.New("map is not allowed")
+		}
+		for name, value := range v {
+			if err := f.Value.Set(fmt.Sprintf("%s: %v", name, value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+			if many {
+				if err := f.Value.Set(items[i]); err != nil {
+					return err
+				}
+			}
+		}
+		if many {
+			return nil
+		}
+		return f.Value.Set(strings.Join(items, ","))
+	}
+	return f.Value.Set(fmt.Sprint(value))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+var (
+	// contribPath is the JSON file of the name to slug mappings of the run by -contrib, empty
+	// without it.
+	contribPath string
+	contribMtx  sync.Mutex
+	contrib     = map[string]string{}
+
+	// mappings are the Epic product slugs of games by normalized name from the shared datasets of
+	// -import-mappings, used after the aliases and the database, before any store request.
+	mappings map[string]string
+)
+
+// addContrib keeps the slug of the Epic product page matched to the game for the shared dataset.
+// Only the name and the slug are kept, nothing of the inputs, the launchers or the decisions.
+func addContrib(r *result) {
+	slug := epicmatch.Slug(r.Link)
+	if len(contribPath) == 0 || len(slug) == 0 || len(r.Store) > 0 {
+		return
+	}
+	contribMtx.Lock()
+	defer contribMtx.Unlock()
+	contrib[strings.TrimSpace(r.Name)] = slug
+}
+
+// writeContrib writes the mappings of the run as a JSON object sorted by name, usable by
+// -import-mappings or as -aliases too.
+func writeContrib() error {
+	contribMtx.Lock()
+	defer contribMtx.Unlock()
+	b, err := json.MarshalIndent(contrib, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(contribPath, append(b, '\n'), 0644)
+}
+
+// loadMappings reads the shared datasets of name to slug or link mappings, files or links, the
+// earlier ones winning for the same name.
+func loadMappings(ctx context.Context, sources []string) error {
+	mappings = map[string]string{}
+	for _, src := range sources {
+		var body io.ReadCloser
+		var err error
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			body, err = matcher.Get(ctx, src)
+		} else {
+			body, err = os.Open(src)
+		}
+		if err != nil {
+			return err
+		}
+		var m map[string]string
+		err = json.NewDecoder(body).Decode(&m)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to parse mappings of %s: %w", src, err)
+		}
+		for name, to := range m {
+			key, to := normName(name), strings.TrimSpace(to)
+			if _, ok := mappings[key]; ok || !safeMapping(to) {
+				continue
+			}
+			mappings[key] = to
+		}
+	}
+	return nil
+}
+
+// safeMapping tells if the mapping of a shared dataset is a bare slug or an Epic product page, so
+// a dataset can't link the cards anywhere else.
+func safeMapping(to string) bool {
+	if strings.Contains(to, "://") {
+		return epicmatch.IsProduct(to)
+	}
+	return len(to) > 0 && !strings.ContainsAny(to, "/?#:\\ ")
+}
+
+// mappingLink returns the link of the game by the imported mappings, if any.
+func mappingLink(name string) (string, bool) {
+	to, ok := mappings[normName(name)]
+	if !ok {
+		return "", false
+	}
+	if strings.HasPrefix(to, "http://") || strings.HasPrefix(to, "https://") {
+		return to, true
+	}
+	return matcher.ProductLink(to), true
+}
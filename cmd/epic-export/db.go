@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// db stores the resolved games by name between runs, nil if disabled, see game.dbKey.
+	db *bolt.DB
+	// rebuild ignores stored matches, forcing all games to be resolved again.
+	rebuild bool
+
+	matchesB = []byte("matches")
+)
+
+// openDB opens or creates the match database.
+func openDB(path string) error {
+	var err error
+	if db, err = bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second}); err != nil {
+		return fmt.Errorf("open match database %s: %w", path, err)
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(matchesB)
+		return err
+	})
+}
+
+// dbGet returns the stored result of the given game key.
+func dbGet(key string) (*result, bool) {
+	if db == nil || rebuild {
+		return nil, false
+	}
+	var r *result
+	err := db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(matchesB).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		r = &result{}
+		return json.Unmarshal(v, r)
+	})
+	if err != nil {
+		slog.Error("failed to read stored match", "game", key, "stage", "database", "err", err)
+		return nil, false
+	}
+	return r, r != nil
+}
+
+// dbPut stores the result of the game key for later runs.
+func dbPut(key string, r *result) {
+	if db == nil {
+		return
+	}
+	v, err := json.Marshal(r)
+	if err == nil {
+		err = db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(matchesB).Put([]byte(key), v)
+		})
+	}
+	if err != nil {
+		slog.Error("failed to store match", "game", r.Name, "stage", "database", "err", err)
+		addFailure(r.Name, "database", err)
+	}
+}
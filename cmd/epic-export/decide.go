@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// deciding is set after the automatic phase, when the games are asked from the user.
+	deciding  atomic.Bool
+	decideMtx sync.Mutex
+	// decideQueue are the games waiting for the user after the automatic phase.
+	decideQueue []*game
+)
+
+// askLater queues the game for the interactive phase with its own copy of the search results, so
+// its work token resolves the other games meanwhile.
+func (g *game) askLater() {
+	g.work = g.clone().work
+	g.asking = true
+	decideMtx.Lock()
+	defer decideMtx.Unlock()
+	decideQueue = append(decideQueue, g)
+}
+
+// decidePass asks the user about the queued games one after the other in the input order, after
+// all the others are resolved.
+func decidePass(ctx context.Context) {
+	deciding.Store(true)
+	decideMtx.Lock()
+	queue := decideQueue
+	decideQueue = nil
+	decideMtx.Unlock()
+	if len(queue) == 0 || ctx.Err() != nil {
+		return
+	}
+	slog.Info("automatic phase done, asking about the rest", "games", len(queue))
+	slices.SortFunc(queue, func(a, b *game) int { return a.index - b.index })
+	for _, g := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+		g.stage("pick")
+		unwatch := watchGame(g, nil)
+		err := g.decide(ctx)
+		unwatch()
+		if err != nil && ctx.Err() == nil {
+			g.log.Error("pick failed", "err", err)
+			addFailure(g.Name, "pick", err)
+		}
+		g.asking = false
+		g.done = ctx.Err() == nil
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// checkDelisted checks the product pages of the games written already by -update, and marks the
+	// cards of the ones gone from the store, by -check-delisted.
+	checkDelisted bool
+	// delisted are the last known links of the written games gone from the store, to their names.
+	delisted = map[string]string{}
+)
+
+// writtenLinks return the product links of the games in an existing output to their names by
+// the format, for the formats marked by markDelisted.
+var writtenLinks = map[string]func(r io.Reader) (map[string]string, error){
+	"html": htmlLinks,
+	"json": jsonLinks,
+}
+
+// findDelisted checks the product pages of the written games, concurrency at a time, and records
+// the gone ones. A page failing to load is kept as it is.
+func findDelisted(ctx context.Context, path, format string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	links, err := writtenLinks[format](f)
+	if err != nil {
+		return fmt.Errorf("failed to read links of %s: %w", path, err)
+	}
+	slog.Info("checking the written product pages", "games", len(links))
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for link, name := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			gone, err := matcher.Delisted(ctx, link)
+			if err != nil {
+				slog.Warn("failed to check product page", "game", name, "stage", "update", "link", link, "err", err)
+				return
+			}
+			if gone {
+				slog.Warn("product page is gone, marking the game delisted", "game", name, "link", link)
+				mtx.Lock()
+				delisted[link] = name
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// htmlLinks returns the product links of the cards to their names, the delisted ones left out by
+// their archive links.
+func htmlLinks(r io.Reader) (map[string]string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	links := map[string]string{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.DataAtom == atom.Div {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode {
+					if c.DataAtom == atom.A {
+						for _, a := range c.Attr {
+							if a.Key == "href" && epicmatch.IsProduct(a.Val) {
+								links[a.Val] = nodeText(c)
+							}
+						}
+					}
+					break
+				}
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return links, nil
+}
+
+// jsonLinks returns the product links of the results to their names, without the delisted ones.
+func jsonLinks(r io.Reader) (map[string]string, error) {
+	var res []result
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return nil, err
+	}
+	links := map[string]string{}
+	for _, r := range res {
+		if !r.Delisted && epicmatch.IsProduct(r.Link) {
+			links[r.Link] = r.Name
+		}
+	}
+	return links, nil
+}
+
+// markDelisted returns the merged output with the cards of the delisted games marked: an HTML card
+// links to the Wayback Machine with a badge, keeping the last known link in data-link, and a JSON
+// result gets the delisted flag and the wayback link.
+func markDelisted(b []byte, format string) []byte {
+	if len(delisted) == 0 {
+		return b
+	}
+	if format == "json" {
+		return markDelistedJSON(b)
+	}
+	badge := []byte(fmt.Sprintf(`<span class="locked">%s</span>`, html.EscapeString(text.delisted)))
+	for link := range delisted {
+		old := []byte(fmt.Sprintf(`<a href="%s">`, html.EscapeString(link)))
+		marked := []byte(fmt.Sprintf(`<a href="%s" data-link="%s">`, html.EscapeString(epicmatch.WaybackLink(link)),
+			html.EscapeString(link)))
+		for start := 0; ; {
+			i := bytes.Index(b[start:], old)
+			if i < 0 {
+				break
+			}
+			i += start
+			end := bytes.Index(b[i:], []byte("</a>"))
+			if end < 0 {
+				break
+			}
+			end += i + len("</a>")
+			card := slices.Concat(marked, b[i+len(old):end], badge)
+			b = slices.Concat(b[:i], card, b[end:])
+			start = i + len(card)
+		}
+	}
+	return b
+}
+
+// markDelistedJSON marks the results of the delisted games, one on each line as jsonOutput writes
+// them. Other lines are kept as they are.
+func markDelistedJSON(b []byte) []byte {
+	lines := bytes.Split(b, []byte("\n"))
+	for i, line := range lines {
+		entry := bytes.TrimSuffix(bytes.TrimSpace(line), []byte(","))
+		if !bytes.HasPrefix(entry, []byte("{")) {
+			continue
+		}
+		var r result
+		if err := json.Unmarshal(entry, &r); err != nil {
+			continue
+		}
+		if _, ok := delisted[r.Link]; !ok || r.Delisted {
+			continue
+		}
+		r.Delisted, r.Wayback = true, epicmatch.WaybackLink(r.Link)
+		marked, err := json.Marshal(&r)
+		if err != nil {
+			continue
+		}
+		lines[i] = bytes.Replace(line, entry, marked, 1)
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
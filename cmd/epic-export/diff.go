@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// diffCmd is the subcommand comparing two exports of the games, like the monthly ones of a
+// library, without any request.
+const diffCmd = "diff"
+
+// renameConfidence is the minimum similarity of a removed and an added name of the same game
+// renamed, like the title of an edition upgrade.
+const renameConfidence = 80
+
+// exportDiff is the change of the games between the exports.
+type exportDiff struct {
+	Added   []diffGame   `json:"added"`
+	Removed []diffGame   `json:"removed"`
+	Renamed []diffRename `json:"renamed"`
+}
+
+// diffGame is an added or removed game, with its logo of the export.
+type diffGame struct {
+	Name string `json:"name"`
+	Logo string `json:"logo,omitempty"`
+}
+
+// diffRename is a game of the old export found by another name in the new one.
+type diffRename struct {
+	Old  string `json:"old"`
+	New  string `json:"new"`
+	Logo string `json:"logo,omitempty"`
+}
+
+// diffExports prints the games added, removed and renamed from the old export to the new one, and
+// writes them to the output path if given, as a what's new page, or JSON for .json.
+func diffExports(ctx context.Context, paths []string, format, outPath string) error {
+	var exports [2][]*game
+	for i, path := range paths {
+		games, err := readInputs(ctx, []string{path}, format)
+		if err != nil {
+			return err
+		}
+		exports[i] = games
+	}
+	d := diffGames(exports[0], exports[1])
+	d.print()
+	if len(outPath) == 0 {
+		return nil
+	}
+	if strings.EqualFold(filepath.Ext(outPath), ".json") {
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(outPath, append(b, '\n'), 0644)
+	}
+	return os.WriteFile(outPath, []byte(d.page()), 0644)
+}
+
+// diffGames compares the games by their normalized names. A removed and an added game are the
+// same one renamed if they have the same source link or logo, or similar names, each removed one
+// paired with the most similar added one.
+func diffGames(old, cur []*game) exportDiff {
+	d := exportDiff{Added: []diffGame{}, Removed: []diffGame{}, Renamed: []diffRename{}}
+	inOld, inCur := map[string]bool{}, map[string]bool{}
+	for _, g := range old {
+		inOld[normName(g.Name)] = true
+	}
+	for _, g := range cur {
+		inCur[normName(g.Name)] = true
+	}
+	var removed, added []*game
+	for _, g := range old {
+		if !inCur[normName(g.Name)] {
+			removed = append(removed, g)
+		}
+	}
+	for _, g := range cur {
+		if !inOld[normName(g.Name)] {
+			added = append(added, g)
+		}
+	}
+	paired := map[*game]bool{}
+	for _, r := range removed {
+		var best *game
+		bestConf := renameConfidence - 1
+		for _, a := range added {
+			if paired[a] {
+				continue
+			}
+			conf := epicmatch.FullSimilarity.Confidence(r.Name, a.Name)
+			// a placeholder logo may be shared by all the games, so it needs some similarity too
+			sameLogo := len(r.Logo) > 0 && r.Logo == a.Logo && conf >= renameConfidence/2
+			if (len(r.Link) > 0 && r.Link == a.Link) || sameLogo {
+				conf = 101
+			}
+			if conf > bestConf {
+				best, bestConf = a, conf
+			}
+		}
+		if best == nil {
+			d.Removed = append(d.Removed, diffGame{Name: r.Name, Logo: r.Logo})
+			continue
+		}
+		paired[best] = true
+		d.Renamed = append(d.Renamed, diffRename{Old: r.Name, New: best.Name, Logo: best.Logo})
+	}
+	for _, a := range added {
+		if !paired[a] {
+			d.Added = append(d.Added, diffGame{Name: a.Name, Logo: a.Logo})
+		}
+	}
+	return d
+}
+
+// print writes the changes to the standard output.
+func (d exportDiff) print() {
+	if len(d.Added)+len(d.Removed)+len(d.Renamed) == 0 {
+		fmt.Println("no changes")
+		return
+	}
+	names := func(games []diffGame) string {
+		list := make([]string, len(games))
+		for i, g := range games {
+			list[i] = g.Name
+		}
+		return strings.Join(list, "\n  ")
+	}
+	if len(d.Added) > 0 {
+		fmt.Printf("added games: %d\n  %s\n", len(d.Added), names(d.Added))
+	}
+	if len(d.Removed) > 0 {
+		fmt.Printf("removed games: %d\n  %s\n", len(d.Removed), names(d.Removed))
+	}
+	if len(d.Renamed) > 0 {
+		fmt.Printf("renamed games: %d\n", len(d.Renamed))
+		for _, r := range d.Renamed {
+			fmt.Printf("  %s -> %s\n", r.Old, r.New)
+		}
+	}
+}
+
+// page returns the what's new page of the changes in the language of -lang, the cards linked to
+// the store search of their names.
+func (d exportDiff) page() string {
+	var b strings.Builder
+	b.WriteString(titledHeader(text.whatsNew))
+	if len(d.Added)+len(d.Removed)+len(d.Renamed) == 0 {
+		fmt.Fprintf(&b, emptyFmt, html.EscapeString(text.noChanges))
+	}
+	section := func(title string, games []diffGame) {
+		if len(games) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, sectionFmt, html.EscapeString(fmt.Sprintf("%s (%d)", title, len(games))))
+		for _, g := range games {
+			fmt.Fprintf(&b, wishFmt, html.EscapeString(matcher.SearchLink(g.Name)), html.EscapeString(g.Name), "", "",
+				html.EscapeString(g.Logo))
+		}
+		b.WriteString(sectionEnd)
+	}
+	section(text.added, d.Added)
+	if len(d.Renamed) > 0 {
+		fmt.Fprintf(&b, sectionFmt, html.EscapeString(fmt.Sprintf("%s (%d)", text.renamed, len(d.Renamed))))
+		for _, r := range d.Renamed {
+			fmt.Fprintf(&b, wishFmt, html.EscapeString(matcher.SearchLink(r.New)), html.EscapeString(r.New),
+				fmt.Sprintf(`<span class="source">%s</span>`, html.EscapeString(r.Old)), "", html.EscapeString(r.Logo))
+		}
+		b.WriteString(sectionEnd)
+	}
+	section(text.removed, d.Removed)
+	b.WriteString(htmlFooter)
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// Exit codes of the run, for the wrappers like cron jobs and CI.
+const (
+	exitOK = 0
+	// exitError is a usage or setup error, or a crash.
+	exitError = 1
+	// exitUnresolved means some games were left for review or failed, by -fail-on-unresolved.
+	exitUnresolved = 2
+	// exitAborted means the run was interrupted before all the games were resolved.
+	exitAborted = 3
+	// exitUnavailable means the fetcher of the store pages couldn't run, like a missing curl.
+	exitUnavailable = 4
+)
+
+var (
+	// failOnUnresolved exits with exitUnresolved if any game is unresolved, by -fail-on-unresolved.
+	failOnUnresolved bool
+	// exitCode is the outcome of the run, set at the end of main.
+	exitCode = exitOK
+	// unavailable is set by the first failure of the fetch backend.
+	unavailable atomic.Bool
+)
+
+// exit exits by the outcome of the run, deferred first in main so the others run before it. A
+// crash is printed like the runtime does, but exits with exitError instead of its 2, which is
+// exitUnresolved here.
+func exit() {
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, debug.Stack())
+		os.Exit(exitError)
+	}
+	os.Exit(exitCode)
+}
+
+// noteUnavailable records if the error of a game is the fetch backend being unavailable, or all
+// the proxies being dead.
+func noteUnavailable(err error) {
+	if errors.Is(err, epicmatch.ErrUnavailable) || errors.Is(err, epicmatch.ErrNoProxy) {
+		unavailable.Store(true)
+	}
+}
+
+// outcome returns the exit code of the finished run by its unresolved games.
+func outcome(ctx context.Context, games []*game) int {
+	switch {
+	case unavailable.Load():
+		return exitUnavailable
+	case ctx.Err() != nil && !paused():
+		return exitAborted
+	case failOnUnresolved && unresolved(games) > 0:
+		return exitUnresolved
+	}
+	return exitOK
+}
+
+// unresolved returns the number of games left for review, unfinished or failed. Skipped ones are
+// decided by the user, so they count as resolved.
+func unresolved(games []*game) int {
+	names := map[string]bool{}
+	for _, g := range games {
+		if !g.done {
+			names[g.Name] = true
+		}
+	}
+	pendMtx.Lock()
+	for _, g := range pending {
+		names[g.Name] = true
+	}
+	pendMtx.Unlock()
+	failMtx.Lock()
+	for _, f := range failures {
+		if f.Kind != kindSkipped {
+			names[f.Game] = true
+		}
+	}
+	failMtx.Unlock()
+	return len(names)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+var (
+	// explainMatches records why each game matched in the results by -explain.
+	explainMatches bool
+	// rankName tells what the rank of the search results is by -matcher.
+	rankName = "Levenshtein distance"
+)
+
+// explain returns why the result of the game matched, with the search result it was taken from,
+// if any.
+func (g *game) explain(r *result, m *epicmatch.Match) string {
+	var why string
+	switch r.Method {
+	case methodAlias:
+		why = "the name is in the aliases file"
+	case methodMapping:
+		why = "the name is in an imported mapping"
+	case methodSlug:
+		why = fmt.Sprintf("the product page of the slug %s has the same name", epicmatch.Slug(r.Link))
+		if len(g.slugVariant) > 0 {
+			why += fmt.Sprintf(", the slug of the name %s", g.slugVariant)
+		}
+	case methodSearch:
+		why = "exact name in the search results"
+		if m != nil && len(m.Store) > 0 {
+			why += " of " + m.Store
+		} else if m != nil && !epicmatch.SameName(m.Name, g.Name) {
+			why += fmt.Sprintf(" of the localized name %q", m.Name)
+		}
+	case methodYear:
+		why = fmt.Sprintf("same name, told apart by the release year %d", g.Year)
+	case methodHash:
+		why = "the logo is like the thumbnail of the search result"
+		if m != nil {
+			why = fmt.Sprintf("the logo is like the thumbnail of %q, hash distance %d", m.Name, g.hashDist)
+		}
+	case methodAuto:
+		why = fmt.Sprintf("best search result%s, at least the auto-accept %d%%", rankOf(m), autoAccept)
+	case methodPick:
+		why = "picked from the search results" + rankOf(m)
+		if len(g.refined) > 0 {
+			why += fmt.Sprintf(", of the refined search %q", g.refined)
+		}
+	case methodImage:
+		why = "picked from the logo search results"
+	case methodTyped:
+		why = "typed link"
+	case methodSource:
+		why = "the page of the game in its source library"
+	case methodCollection:
+		why = fmt.Sprintf("a collection of %d components, each by its exact name", len(r.Parts))
+	case methodNone:
+		why = "kept without a link"
+	default:
+		return ""
+	}
+	if m != nil && m.Link != r.Link {
+		why += fmt.Sprintf(", written as %s by -prefer", r.Link)
+	}
+	return why
+}
+
+// rankOf describes how close the name of the search result is to the game, empty without one.
+func rankOf(m *epicmatch.Match) string {
+	switch {
+	case m == nil:
+		return ""
+	case m.Rank == 0 && m.Confidence < 100:
+		return fmt.Sprintf(" %q, a substring match, confidence %d%%", m.Name, m.Confidence)
+	}
+	return fmt.Sprintf(" %q at %s %d, confidence %d%%", m.Name, rankName, m.Rank, m.Confidence)
+}
+
+// storedExplain returns why the stored result of an earlier run matched, by -explain.
+func (g *game) storedExplain(r *result) string {
+	if !explainMatches {
+		return ""
+	}
+	why := r.Explain
+	if len(why) == 0 {
+		why = g.explain(r, nil)
+	}
+	return "stored by an earlier run, " + why
+}
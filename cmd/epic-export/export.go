@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+const (
+	notionPages   = "https://api.notion.com/v1/pages"
+	notionVersion = "2022-06-28"
+	airtableAPI   = "https://api.airtable.com/v0/%s/%s"
+	// airtableBatch is the maximum number of records created by a request.
+	airtableBatch = 10
+	// exportRetries is the number of tries of a rate limited export request.
+	exportRetries = 3
+	// exportTimeout is the maximum time of an export request, exportGrace is the time of exporting
+	// the results of an interrupted run.
+	exportTimeout = 30 * time.Second
+	exportGrace   = time.Minute
+)
+
+// exporters push the results to an external service by -export, after the output is written.
+var exporters = map[string]func(ctx context.Context, all []*result) error{
+	"notion":   exportNotion,
+	"airtable": exportAirtable,
+}
+
+var (
+	// exportTo is the key of the exporter, empty without -export.
+	exportTo string
+	// notionDB is the ID of the Notion database of the pages, and notionToken is the secret of its
+	// integration.
+	notionDB, notionToken string
+	// airtableBase and airtableTable tell the table of the records, and airtableToken is the
+	// personal access token writing it.
+	airtableBase, airtableTable, airtableToken string
+
+	exportMtx sync.Mutex
+	exported  []*result
+
+	// exportClient does the requests of the exporters.
+	exportClient = &http.Client{Timeout: exportTimeout}
+)
+
+// addExport keeps the result for the exporter.
+func addExport(r *result) {
+	exportMtx.Lock()
+	defer exportMtx.Unlock()
+	exported = append(exported, r)
+}
+
+// export pushes the results of the run by the exporter in the input order.
+func export(ctx context.Context) error {
+	exportMtx.Lock()
+	defer exportMtx.Unlock()
+	if len(exported) == 0 {
+		return nil
+	}
+	sortResults("input", exported)
+	slog.Info("exporting results", "to", exportTo, "count", len(exported))
+	return exporters[exportTo](ctx, exported)
+}
+
+// checkExport validates the flags of the exporter, taking the tokens from the environment by
+// default.
+func checkExport() error {
+	switch exportTo {
+	case "":
+		return nil
+	case "notion":
+		if len(notionToken) == 0 {
+			notionToken = os.Getenv("NOTION_TOKEN")
+		}
+		if len(notionDB) == 0 || len(notionToken) == 0 {
+			return errors.New("notion export needs -notion-db and -notion-token or NOTION_TOKEN")
+		}
+	case "airtable":
+		if len(airtableToken) == 0 {
+			airtableToken = os.Getenv("AIRTABLE_TOKEN")
+		}
+		if len(airtableBase) == 0 || len(airtableTable) == 0 || len(airtableToken) == 0 {
+			return errors.New("airtable export needs -airtable-base, -airtable-table and -airtable-token or AIRTABLE_TOKEN")
+		}
+	default:
+		return fmt.Errorf("unknown export %q, use notion or airtable", exportTo)
+	}
+	return nil
+}
+
+// exportNotion creates a page of each result in the Notion database. The database needs the
+// properties Name (title), Link and Logo (URL), Price (text) and Confidence (number).
+func exportNotion(ctx context.Context, all []*result) error {
+	headers := map[string]string{"Notion-Version": notionVersion}
+	for i, r := range all {
+		if i > 0 {
+			if err := sleep(ctx, time.Second/3); err != nil { // the average rate limit of the API
+				return err
+			}
+		}
+		page := map[string]any{
+			"parent": map[string]string{"database_id": notionDB},
+			"properties": map[string]any{
+				"Name":       map[string]any{"title": notionText(r.Name)},
+				"Link":       map[string]any{"url": optional(r.Link)},
+				"Logo":       map[string]any{"url": optional(r.Logo)},
+				"Price":      map[string]any{"rich_text": notionText(priceText(r.Price))},
+				"Confidence": map[string]any{"number": r.Confidence},
+			},
+		}
+		if len(r.Logo) > 0 {
+			page["cover"] = map[string]any{"type": "external", "external": map[string]string{"url": r.Logo}}
+		}
+		if err := postExport(ctx, notionPages, notionToken, headers, page); err != nil {
+			return fmt.Errorf("failed to export %s to notion: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// notionText returns the rich text of the string, empty for no text.
+func notionText(s string) []any {
+	if len(s) == 0 {
+		return []any{}
+	}
+	return []any{map[string]any{"text": map[string]string{"content": s}}}
+}
+
+// optional returns nil for the empty string, as the APIs reject empty URLs.
+func optional(s string) any {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// exportAirtable creates a record of each result in the Airtable table, in batches. The table needs
+// the fields Name, Link, Logo and Price (text or URL) and Confidence (number).
+func exportAirtable(ctx context.Context, all []*result) error {
+	link := fmt.Sprintf(airtableAPI, url.PathEscape(airtableBase), url.PathEscape(airtableTable))
+	for start := 0; start < len(all); start += airtableBatch {
+		if start > 0 {
+			if err := sleep(ctx, time.Second/5); err != nil { // the rate limit of a base
+				return err
+			}
+		}
+		batch := all[start:min(start+airtableBatch, len(all))]
+		records := make([]any, len(batch))
+		for i, r := range batch {
+			fields := map[string]any{"Name": r.Name, "Confidence": r.Confidence}
+			if len(r.Link) > 0 {
+				fields["Link"] = r.Link
+			}
+			if len(r.Logo) > 0 {
+				fields["Logo"] = r.Logo
+			}
+			if p := priceText(r.Price); len(p) > 0 {
+				fields["Price"] = p
+			}
+			records[i] = map[string]any{"fields": fields}
+		}
+		body := map[string]any{"records": records, "typecast": true}
+		if err := postExport(ctx, link, airtableToken, nil, body); err != nil {
+			return fmt.Errorf("failed to export results from %s to airtable: %w", batch[0].Name, err)
+		}
+	}
+	return nil
+}
+
+// priceText formats the price as plain text, empty without a price.
+func priceText(p *epicmatch.Price) string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Free:
+		return "Free"
+	case p.Discount > 0:
+		return fmt.Sprintf("%s (-%d%% from %s)", p.Current, p.Discount, p.Original)
+	}
+	return p.Current
+}
+
+// postExport posts the body as JSON with the bearer token, waiting and trying again when rate
+// limited.
+func postExport(ctx context.Context, link, token string, headers map[string]string, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	for try := 1; ; try++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, link, bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("authorization", "Bearer "+token)
+		req.Header.Set("content-type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := exportClient.Do(req)
+		if err != nil {
+			return err
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && try < exportRetries:
+			wait := time.Second
+			if s, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil {
+				wait = time.Duration(s) * time.Second
+			}
+			if err = sleep(ctx, wait); err != nil {
+				return err
+			}
+		case resp.StatusCode >= 300:
+			return fmt.Errorf("wrong status %s: %s", resp.Status, bytes.TrimSpace(msg))
+		default:
+			return nil
+		}
+	}
+}
+
+// sleep waits for the duration, or returns the error of the context if it's done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// Kinds of failures, by the error.
+const (
+	kindRetries  = "retries exhausted"
+	kindParse    = "parse failure"
+	kindNoResult = "no results"
+	kindCanceled = "canceled"
+	kindSkipped  = "skipped"
+	kindStalled  = "stalled"
+	kindOther    = "error"
+)
+
+var (
+	// failuresPath is the JSON file of the failures of the run.
+	failuresPath string
+	failMtx      sync.Mutex
+	failures     []failure
+)
+
+// failure is an error of a game in a stage of resolving it.
+type failure struct {
+	Game  string `json:"game"`
+	Stage string `json:"stage"`
+	Kind  string `json:"kind"`
+	Error string `json:"error"`
+}
+
+// addFailure records the error of the game for the report at the end. A nil error is a skip.
+func addFailure(name, stage string, err error) {
+	noteUnavailable(err)
+	f := failure{Game: name, Stage: stage, Kind: kindOf(err)}
+	if err != nil {
+		f.Error = err.Error()
+	}
+	failMtx.Lock()
+	defer failMtx.Unlock()
+	failures = append(failures, f)
+}
+
+// dropSkip forgets the skip of the game, when deciding again.
+func dropSkip(name string) {
+	failMtx.Lock()
+	defer failMtx.Unlock()
+	failures = slices.DeleteFunc(failures, func(f failure) bool { return f.Game == name && f.Kind == kindSkipped })
+}
+
+// kindOf returns the kind of the failure by the error.
+func kindOf(err error) string {
+	switch {
+	case err == nil:
+		return kindSkipped
+	case errors.Is(err, epicmatch.ErrTooManyRetries):
+		return kindRetries
+	case errors.Is(err, epicmatch.ErrParse):
+		return kindParse
+	case errors.Is(err, epicmatch.ErrNoResults):
+		return kindNoResult
+	case errors.Is(err, errStalled):
+		return kindStalled
+	case errors.Is(err, context.Canceled):
+		return kindCanceled
+	}
+	return kindOther
+}
+
+// writeFailures prints the table of failures to w, and writes them to the JSON file. A stale
+// file is removed if there are none.
+func writeFailures(w io.Writer) error {
+	failMtx.Lock()
+	defer failMtx.Unlock()
+	if len(failures) == 0 {
+		if err := os.Remove(failuresPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Game < failures[j].Game })
+	fmt.Fprintf(w, "\n%d failures:\n", len(failures))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "GAME\tSTAGE\tKIND\tERROR")
+	for _, f := range failures {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Game, f.Stage, f.Kind, f.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(failuresPath, b, 0644)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// fallbacks are the keys of the enabled fallback stores, in order of searching.
+var fallbacks []string
+
+// parseFallbacks enables the comma separated list of fallback stores.
+func parseFallbacks(list string) error {
+	for _, key := range strings.Split(list, ",") {
+		if key = strings.TrimSpace(key); len(key) == 0 {
+			continue
+		}
+		if _, err := epicmatch.FallbackStore(key); err != nil {
+			return err
+		}
+		fallbacks = append(fallbacks, key)
+	}
+	return nil
+}
+
+// searchFallback searches the fallback stores in order, when there's no result in the Epic store.
+// Returns true if the game was written by an exact name match, otherwise the results of all fallback
+// stores are added to the choices by rank, editions grouped.
+func (g *game) searchFallback(ctx context.Context) bool {
+	var all []epicmatch.Match
+	for _, key := range fallbacks {
+		matches, err := matcher.SearchFallback(ctx, key, g.Name)
+		if err != nil {
+			g.log.Warn("fallback store search failed", "store", key, "err", err)
+			addFailure(g.Name, "fallback "+key, err)
+			continue
+		}
+		for _, m := range matches {
+			if epicmatch.SameName(m.Name, g.Name) {
+				m.Confidence = 100
+				g.writeMatch(ctx, methodSearch, &m)
+				return true
+			}
+		}
+		all = append(all, matches...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Rank < all[j].Rank })
+	g.work.add(epicmatch.GroupEditions(all)...)
+	return false
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+	"golang.org/x/net/html"
+)
+
+const (
+	// feedItems is the maximum number of entries kept in the feed, the oldest ones are dropped.
+	feedItems = 100
+	feedTitle = "My new games"
+	jsonFeed  = "https://jsonfeed.org/version/1.1"
+)
+
+var (
+	// feedPath is the RSS, or for .json the JSON Feed file of the new games by -feed, empty
+	// without it.
+	feedPath string
+
+	feedMtx sync.Mutex
+	fed     []*result
+)
+
+// feedItem is a game in the feed of either format.
+type feedItem struct {
+	ID, URL, Title, Image string
+	Date                  time.Time
+}
+
+// rss is the RSS 2.0 document of the feed.
+type rss struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title       string    `xml:"title"`
+		Link        string    `xml:"link"`
+		Description string    `xml:"description"`
+		Items       []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        string        `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssEnclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// jsonFeedDoc is the JSON Feed document of the feed.
+type jsonFeedDoc struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	Image         string    `json:"image,omitempty"`
+	ContentHTML   string    `json:"content_html"`
+	DatePublished time.Time `json:"date_published"`
+}
+
+// addFeed keeps the new game for the feed, if it has a link.
+func addFeed(r *result) {
+	if len(r.Link) == 0 {
+		return
+	}
+	feedMtx.Lock()
+	defer feedMtx.Unlock()
+	fed = append(fed, r)
+}
+
+// writeFeed adds the new games of the run to the start of the feed. Games already in it, like
+// after an interrupted run, are not added again.
+func writeFeed() error {
+	feedMtx.Lock()
+	defer feedMtx.Unlock()
+	isJSON := strings.EqualFold(filepath.Ext(feedPath), ".json")
+	items, err := readFeed(feedPath, isJSON)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read feed %s: %w", feedPath, err)
+	}
+	sortResults("input", fed)
+	now := time.Now().UTC().Truncate(time.Second)
+	var added []feedItem
+	for _, r := range fed {
+		if !slices.ContainsFunc(items, func(it feedItem) bool { return it.ID == r.Link }) {
+			added = append(added, feedItem{ID: r.Link, URL: r.Link, Title: r.Name, Image: r.Logo, Date: now})
+		}
+	}
+	if len(added) == 0 && err == nil {
+		return nil
+	}
+	items = append(added, items...)
+	items = items[:min(len(items), feedItems)]
+	var b []byte
+	if isJSON {
+		b, err = jsonFeedBytes(items)
+	} else {
+		b, err = rssBytes(items)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(feedPath, b, 0644)
+}
+
+// readFeed returns the items of the existing feed.
+func readFeed(path string, isJSON bool) ([]feedItem, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []feedItem
+	if isJSON {
+		var doc jsonFeedDoc
+		if err = json.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		for _, it := range doc.Items {
+			items = append(items, feedItem{ID: it.ID, URL: it.URL, Title: it.Title, Image: it.Image,
+				Date: it.DatePublished})
+		}
+		return items, nil
+	}
+	var doc rss
+	if err = xml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	for _, it := range doc.Channel.Items {
+		fi := feedItem{ID: it.GUID, URL: it.Link, Title: it.Title}
+		fi.Date, _ = time.Parse(time.RFC1123Z, it.PubDate)
+		if it.Enclosure != nil {
+			fi.Image = it.Enclosure.URL
+		}
+		items = append(items, fi)
+	}
+	return items, nil
+}
+
+// content is the HTML of the item, its logo linking to the game.
+func (it *feedItem) content() string {
+	link := html.EscapeString(it.URL)
+	if len(it.Image) == 0 {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, link, html.EscapeString(it.Title))
+	}
+	return fmt.Sprintf(`<a href="%s"><img src="%s" alt="%s"/></a>`, link, html.EscapeString(it.Image),
+		html.EscapeString(it.Title))
+}
+
+func rssBytes(items []feedItem) ([]byte, error) {
+	doc := rss{Version: "2.0"}
+	doc.Channel.Title, doc.Channel.Link, doc.Channel.Description = feedTitle, epicmatch.Host,
+		"Games newly added to the library"
+	for _, it := range items {
+		ri := rssItem{Title: it.Title, Link: it.URL, GUID: it.ID, PubDate: it.Date.Format(time.RFC1123Z),
+			Description: it.content()}
+		if len(it.Image) > 0 {
+			ri.Enclosure = &rssEnclosure{URL: it.Image, Type: "image/jpeg"}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, ri)
+	}
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
+
+func jsonFeedBytes(items []feedItem) ([]byte, error) {
+	doc := jsonFeedDoc{Version: jsonFeed, Title: feedTitle}
+	for _, it := range items {
+		doc.Items = append(doc.Items, jsonFeedItem{ID: it.ID, URL: it.URL, Title: it.Title, Image: it.Image,
+			ContentHTML: it.content(), DatePublished: it.Date})
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	return append(b, '\n'), err
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// giveawayStore is the -giveaways source of the current and upcoming store giveaways.
+const giveawayStore = "epic"
+
+// giveaways are the free periods of the games, loaded if asked for.
+var giveaways []epicmatch.Giveaway
+
+// loadGiveaways gets the giveaways from the store, a file or a link.
+func loadGiveaways(ctx context.Context, source string) (err error) {
+	if source == giveawayStore {
+		source = ""
+	}
+	giveaways, err = matcher.Giveaways(ctx, source)
+	return err
+}
+
+// addGiveaways annotates the result with the periods it was given away free, by the product slug
+// or the name.
+func addGiveaways(r *result) {
+	slug, name := epicmatch.Slug(r.Link), normName(r.Name)
+	for _, ga := range giveaways {
+		if (len(slug) > 0 && ga.Slug == slug) || normName(ga.Title) == name {
+			r.Giveaways = append(r.Giveaways, ga)
+		}
+	}
+}
+
+// giveawayHTML formats the giveaway dates as a badge, if any.
+func giveawayHTML(gs []epicmatch.Giveaway) string {
+	if len(gs) == 0 {
+		return ""
+	}
+	dates := make([]string, len(gs))
+	for i, ga := range gs {
+		dates[i] = ga.Start.Format("2006-01-02")
+	}
+	return fmt.Sprintf(`<span class="giveaway">%s %s</span>`, html.EscapeString(strings.ToLower(text.free)),
+		html.EscapeString(strings.Join(dates, ", ")))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+const (
+	sectionFmt = `<details open><summary>%s</summary><section>
+`
+	sectionEnd = "</section></details>\n"
+)
+
+// groupers return the section of a result by -group-by, empty for the other section written last.
+var groupers = map[string]func(r *result) string{
+	"source": func(r *result) string { return strings.Join(r.Sources, ", ") },
+	"letter": func(r *result) string {
+		first, _ := utf8.DecodeRuneInString(r.Name)
+		if !unicode.IsLetter(first) {
+			return "#"
+		}
+		return string(unicode.ToUpper(first))
+	},
+	"genre": func(r *result) string {
+		if r.Metadata == nil || len(r.Metadata.Genres) == 0 {
+			return ""
+		}
+		return r.Metadata.Genres[0]
+	},
+	"type": func(r *result) string {
+		switch r.Category {
+		case epicmatch.TypeEditors:
+			return text.editors
+		case epicmatch.TypeApps:
+			return text.apps
+		}
+		return text.games
+	},
+	"matched": func(r *result) string {
+		switch {
+		case len(r.Link) == 0:
+			return text.unmatched
+		case len(r.Store) > 0:
+			return text.otherStores
+		}
+		return text.matched
+	},
+}
+
+// grouper is the section of the results in the HTML output, nil without -group-by.
+var grouper func(r *result) string
+
+// parseGroupBy sets the grouper by its key.
+func parseGroupBy(key string) error {
+	if len(key) == 0 {
+		return nil
+	}
+	g, ok := groupers[key]
+	if !ok {
+		return fmt.Errorf("unknown group %q, use source, letter, genre, type or matched", key)
+	}
+	grouper = g
+	return nil
+}
+
+// groupResults sorts the results by their section, keeping their order within it.
+func groupResults(all []*result) {
+	if grouper == nil {
+		return
+	}
+	slices.SortStableFunc(all, func(a, b *result) int {
+		ga, gb := grouper(a), grouper(b)
+		switch {
+		case ga == gb:
+			return 0
+		case len(ga) == 0:
+			return 1
+		case len(gb) == 0:
+			return -1
+		}
+		return strings.Compare(ga, gb)
+	})
+}
+
+// section returns the heading of the section of the result.
+func section(r *result) string {
+	if s := grouper(r); len(s) > 0 {
+		return s
+	}
+	return text.other
+}
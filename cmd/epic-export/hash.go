@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// hashResults is the maximum number of search result thumbnails compared with the source logo.
+const hashResults = 5
+
+// hashDistance is the maximum hash distance of the source logo and a result thumbnail to take the
+// result without asking, 0 disables comparing them.
+var hashDistance int
+
+// byLogoHash returns the search result whose thumbnail looks like the source logo, if there's
+// only one within hashDistance, nil otherwise.
+func (g *game) byLogoHash(ctx context.Context) *epicmatch.Match {
+	if hashDistance == 0 || len(g.Logo) == 0 {
+		return nil
+	}
+	logo, err := matcher.ImageHash(ctx, g.Logo)
+	if err != nil {
+		g.log.Debug("failed to hash logo", "err", err)
+		return nil
+	}
+	var found *epicmatch.Match
+	dist, checked := 0, 0
+	items := g.work.items
+	for i := range items {
+		if len(items[i].Image) == 0 {
+			continue
+		}
+		if checked++; checked > hashResults {
+			break
+		}
+		h, err := matcher.ImageHash(ctx, items[i].Image)
+		if err != nil {
+			g.log.Debug("failed to hash thumbnail", "match", items[i].Name, "err", err)
+			continue
+		}
+		if d := epicmatch.HashDistance(logo, h); d <= hashDistance {
+			if found != nil {
+				return nil // ambiguous, like the editions of the same art
+			}
+			found, dist = &items[i], d
+		}
+	}
+	if found != nil {
+		g.log.Info("logo matches thumbnail", "match", found.Name, "distance", dist)
+		g.hashDist = dist
+	}
+	return found
+}
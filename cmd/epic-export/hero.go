@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+	"golang.org/x/net/html"
+)
+
+// The images of the HTML cards by -images.
+const (
+	imagesLogo = "logo" // the logo of the input
+	imagesHero = "hero" // the landscape artwork of the store page, the logo without it
+	imagesBoth = "both" // the artwork above the logo
+)
+
+// images are the images of the HTML cards, see imagesLogo.
+var images = imagesLogo
+
+// addHero fills in the landscape artwork of the result from its product page, if asked for.
+func addHero(ctx context.Context, r *result) {
+	if images == imagesLogo || len(r.Hero) > 0 || dryRun || !epicmatch.IsProduct(r.Link) || r.Locked {
+		return
+	}
+	var err error
+	if r.Hero, err = matcher.HeroImage(ctx, r.Link); err != nil {
+		slog.Warn("failed to get hero image", "game", r.Name, "stage", "enrich", "err", err)
+		addFailure(r.Name, "hero image", err)
+	}
+}
+
+// imagesHTML formats the images of the card by -images. The artwork falls back to the logo if it
+// fails to load.
+func imagesHTML(r *result) string {
+	logo := fmt.Sprintf(`<br/><img loading="lazy" src="%s"</img>`, html.EscapeString(r.Logo))
+	if len(r.Hero) == 0 {
+		return logo
+	}
+	var fallback string
+	if images == imagesHero && len(r.Logo) > 0 {
+		src, _ := json.Marshal(r.Logo)
+		fallback = fmt.Sprintf(` onerror="%s"`, html.EscapeString("this.onerror=null;this.src="+string(src)))
+	}
+	hero := fmt.Sprintf(`<br/><img loading="lazy" src="%s"%s</img>`, html.EscapeString(r.Hero), fallback)
+	if images == imagesBoth {
+		return hero + logo
+	}
+	return hero
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var (
+	// hookResolved and hookUnresolved are the commands run for each result with a link, and for
+	// the ones without it or skipped, empty without -hook-resolved and -hook-unresolved.
+	hookResolved, hookUnresolved string
+	// hooks are the results waiting for their command, run one by one in order.
+	hooks     chan *result
+	hooksDone chan struct{}
+)
+
+// startHooks starts running the hooks of the results, if any is set.
+func startHooks() {
+	if len(hookResolved) == 0 && len(hookUnresolved) == 0 {
+		return
+	}
+	hooks, hooksDone = make(chan *result, concurrency), make(chan struct{})
+	go func() {
+		defer close(hooksDone)
+		for r := range hooks {
+			runHook(r)
+		}
+	}()
+}
+
+// addHook runs the hook of the result after the previous ones.
+func addHook(r *result) {
+	if hooks != nil {
+		hooks <- r
+	}
+}
+
+// stopHooks waits for the hooks of all results.
+func stopHooks() {
+	if hooks != nil {
+		close(hooks)
+		<-hooksDone
+	}
+}
+
+// runHook runs the command of the result with its JSON on stdin, by the shell of the system so
+// pipes and quoting work. It isn't killed on interrupt, so the scripts get the results written.
+func runHook(r *result) {
+	line := hookResolved
+	if len(r.Link) == 0 {
+		line = hookUnresolved
+	}
+	if len(strings.TrimSpace(line)) == 0 {
+		return
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		slog.Error("failed to marshal result for hook", "game", r.Name, "stage", "hook", "err", err)
+		return
+	}
+	cmd := exec.Command("sh", "-c", line)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", line)
+	}
+	cmd.Stdin = bytes.NewReader(b)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		slog.Warn("hook failed", "game", r.Name, "stage", "hook", "cmd", line, "err", err, "output", strings.TrimSpace(string(out)))
+		addFailure(r.Name, "hook", err)
+	} else if len(out) > 0 {
+		slog.Debug("hook done", "game", r.Name, "stage", "hook", "output", strings.TrimSpace(string(out)))
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// ignoreNames are the normalized names of -ignore, ignorePatterns are its globs.
+	ignoreNames    = map[string]bool{}
+	ignorePatterns []*regexp.Regexp
+	// ignored are the input games left out by -ignore, listed by the summary.
+	ignored []string
+)
+
+// loadIgnore reads the games to leave out, a name or a glob like "* Demo" per line. Names are
+// compared like the aliases, globs by * and ? ignoring case. Empty lines and the ones starting with
+// # are skipped.
+func loadIgnore(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.ContainsAny(line, "*?") {
+			ignoreNames[normName(line)] = true
+			continue
+		}
+		expr := regexp.QuoteMeta(line)
+		expr = strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(expr)
+		ignorePatterns = append(ignorePatterns, regexp.MustCompile("(?i)^"+expr+"$"))
+	}
+	if err = sc.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
+
+// isIgnored tells if the game is left out by -ignore.
+func isIgnored(name string) bool {
+	name = strings.TrimSpace(name)
+	return ignoreNames[normName(name)] || slices.ContainsFunc(ignorePatterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(name)
+	})
+}
+
+// dropIgnored returns the games without the ones left out by -ignore, recording their names.
+func dropIgnored(games []*game) []*game {
+	if len(ignoreNames) == 0 && len(ignorePatterns) == 0 {
+		return games
+	}
+	return slices.DeleteFunc(games, func(g *game) bool {
+		if !isIgnored(g.Name) {
+			return false
+		}
+		ignored = append(ignored, strings.TrimSpace(g.Name))
+		return true
+	})
+}
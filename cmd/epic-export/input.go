@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// inputs read the games from exported files, or from the account by format name.
+var inputs = map[string]func(ctx context.Context, path string) ([]*game, error){
+	"epic":    readEpic,
+	"prime":   readPrime,
+	"itch":    readItch,
+	"library": readLibrary,
+}
+
+// launchers are the names of the input formats on the source badges.
+var launchers = map[string]string{
+	"epic":    "Epic",
+	"prime":   "Prime Gaming",
+	"itch":    "itch.io",
+	"library": "Epic",
+}
+
+// Column and field names of the Prime Gaming exports, lower case, by precedence.
+var (
+	primeNames = []string{"title", "name", "game", "gametitle", "game title"}
+	primeLogos = []string{"image", "imageurl", "image url", "boxart", "box art", "logo"}
+	primeYears = []string{"year", "releaseyear", "release year", "release_year", "released", "releasedate",
+		"release date", "release_date"}
+)
+
+// playStats are the optional playtime and achievement counts of the game in the input JSON, like
+// in the exports of some launchers, passed through to the results.
+type playStats struct {
+	// Playtime is in minutes.
+	Playtime          int `json:"playtime,omitempty"`
+	Achievements      int `json:"achievements,omitempty"`
+	AchievementsTotal int `json:"achievementsTotal,omitempty"`
+}
+
+type appData struct {
+	Data data `json:"data"`
+}
+
+type data struct {
+	Applications []*game `json:"applications"`
+}
+
+// readInputs reads and merges the games of all input files, and the files in the input
+// directories. An input can override the format by a prefix, like prime:claimed.csv. Games of
+// the same normalized name are merged, with all the sources on their badges for many inputs.
+func readInputs(ctx context.Context, list []string, format string) ([]*game, error) {
+	type input struct{ path, format string }
+	var ins []input
+	for _, path := range list {
+		f := format
+		if pre, rest, ok := strings.Cut(path, ":"); ok && inputs[pre] != nil {
+			f, path = pre, rest
+		}
+		fi, err := os.Stat(path)
+		if err != nil || !fi.IsDir() {
+			// missing files fail on reading, accounts have no path
+			ins = append(ins, input{path, f})
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				ins = append(ins, input{filepath.Join(path, e.Name()), f})
+			}
+		}
+	}
+	var games []*game
+	byName := map[string][]*game{}
+	for _, in := range ins {
+		read, ok := inputs[in.format]
+		if !ok {
+			return nil, fmt.Errorf("unknown input format %q, use epic, prime, itch or library", in.format)
+		}
+		found, err := read(ctx, in.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range found {
+			key := normName(g.Name)
+			if len(ins) > 1 {
+				g.Sources = []string{launchers[in.format]}
+			}
+			same := byName[key]
+			// games of the same name are different by another known release year
+			i := slices.IndexFunc(same, func(o *game) bool { return o.Year == 0 || g.Year == 0 || o.Year == g.Year })
+			if i >= 0 {
+				same[i].merge(g)
+				continue
+			}
+			byName[key] = append(same, g)
+			games = append(games, g)
+		}
+	}
+	for _, same := range byName {
+		for _, g := range same {
+			g.dup = len(same) > 1
+		}
+	}
+	return games, nil
+}
+
+// normName returns the name normalized, lower case, with only its letters and digits, for finding
+// duplicates.
+func normName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, epicmatch.NormalizeName(name))
+}
+
+// merge adds the sources of the same game from another input, and its missing details.
+func (g *game) merge(o *game) {
+	for _, s := range o.Sources {
+		if !slices.Contains(g.Sources, s) {
+			g.Sources = append(g.Sources, s)
+		}
+	}
+	if len(g.Logo) == 0 {
+		g.Logo = o.Logo
+	}
+	if len(g.Link) == 0 {
+		g.Link, g.Source = o.Link, o.Source
+	}
+	if g.Year == 0 {
+		g.Year = o.Year
+	}
+	// the stats of the most played copy
+	if o.Playtime > g.Playtime || g.playStats == (playStats{}) {
+		g.playStats = o.playStats
+	}
+	// owned by any of the inputs
+	g.Wishlist = g.Wishlist && o.Wishlist
+}
+
+// stdio is the path of reading the input from stdin, or writing the output to stdout.
+const stdio = "-"
+
+// openInput opens the input file, or stdin for the path "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == stdio {
+		return hashInput(path, io.NopCloser(os.Stdin)), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return hashInput(path, f), nil
+}
+
+// readEpic reads the authorized apps JSON of the Epic account. Malformed entries are logged with
+// their position and left out, or kept without an invalid logo, only an invalid document fails.
+func readEpic(_ context.Context, path string) ([]*game, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		slog.Warn("empty input", "path", path)
+		return nil, nil
+	}
+	var games []*game
+	var malformed int
+	err = eachApp(b, func(i int, off int64, raw json.RawMessage) {
+		g, err := checkApp(raw)
+		if err != nil {
+			line, col := lineCol(b, off)
+			slog.Warn("malformed input entry", "path", path, "entry", i, "line", line, "col", col, "err", err)
+			if g == nil {
+				malformed++
+				return
+			}
+		}
+		games = append(games, g)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if len(games) == 0 && malformed > 0 {
+		return nil, fmt.Errorf("all the %d entries of %s are malformed", malformed, path)
+	}
+	return games, nil
+}
+
+// eachApp calls found for the entries of data.applications in the document with their index and
+// offset, skipping the other fields. Syntax errors and a missing array are returned with their
+// position.
+func eachApp(b []byte, found func(i int, off int64, raw json.RawMessage)) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	posErr := func(err error) error {
+		line, col := lineCol(b, dec.InputOffset())
+		var se *json.SyntaxError
+		if errors.As(err, &se) {
+			line, col = lineCol(b, se.Offset)
+		}
+		return fmt.Errorf("line %d, col %d: %w", line, col, err)
+	}
+	var inObject func(path []string) (bool, error)
+	inObject = func(path []string) (bool, error) {
+		if t, err := dec.Token(); err != nil {
+			return false, posErr(err)
+		} else if t != json.Delim('{') {
+			return false, posErr(fmt.Errorf("%s must be an object", strings.Join(path, ".")))
+		}
+		var ok bool
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return false, posErr(err)
+			}
+			key, _ := t.(string)
+			switch {
+			case len(path) == 0 && key == "data":
+				if ok, err = inObject([]string{"data"}); err != nil {
+					return false, err
+				}
+			case len(path) == 1 && key == "applications":
+				if t, err = dec.Token(); err != nil {
+					return false, posErr(err)
+				} else if t != json.Delim('[') {
+					return false, posErr(errors.New("data.applications must be an array"))
+				}
+				for i := 0; dec.More(); i++ {
+					off := entryStart(b, dec.InputOffset())
+					var raw json.RawMessage
+					if err = dec.Decode(&raw); err != nil {
+						return false, posErr(err)
+					}
+					found(i, off, raw)
+				}
+				if _, err = dec.Token(); err != nil {
+					return false, posErr(err)
+				}
+				ok = true
+			default:
+				var skip json.RawMessage
+				if err = dec.Decode(&skip); err != nil {
+					return false, posErr(err)
+				}
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return false, posErr(err)
+		}
+		return ok, nil
+	}
+	ok, err := inObject(nil)
+	if err == nil && !ok {
+		err = errors.New("no data.applications array")
+	}
+	return err
+}
+
+// checkApp decodes an entry of the applications. It returns an error and no game for an entry of
+// wrong types or without a name, and an error with the game without its logo for an invalid logo
+// link.
+func checkApp(raw json.RawMessage) (*game, error) {
+	var g game
+	if err := json.Unmarshal(raw, &g); err != nil {
+		var te *json.UnmarshalTypeError
+		if errors.As(err, &te) {
+			return nil, fmt.Errorf("%s must be %s, not %s", te.Field, te.Type, te.Value)
+		}
+		return nil, err
+	}
+	if g.Name = strings.TrimSpace(g.Name); len(g.Name) == 0 {
+		return nil, errors.New("missing applicationName")
+	}
+	if len(g.Logo) > 0 {
+		if u, err := url.Parse(g.Logo); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			logo := g.Logo
+			g.Logo = ""
+			return &g, fmt.Errorf("invalid logo URL %q of %s", logo, g.Name)
+		}
+	}
+	return &g, nil
+}
+
+// entryStart returns the offset of the next value after off, past the whitespace and the comma
+// before it.
+func entryStart(b []byte, off int64) int64 {
+	for off < int64(len(b)) && strings.IndexByte(" \t\r\n,", b[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// lineCol returns the line and column of the offset in the document, both from 1.
+func lineCol(b []byte, off int64) (line, col int) {
+	off = min(off, int64(len(b)))
+	before := b[:off]
+	line = bytes.Count(before, []byte("\n")) + 1
+	return line, int(off) - bytes.LastIndexByte(before, '\n')
+}
+
+// readPrime reads a Prime Gaming claimed games list, a CSV file with a header row, or a JSON
+// array of objects, optionally under "games". Names, logos and release years are found by the usual
+// column names, like title, image and year. Stdin is CSV unless it starts like JSON.
+func readPrime(_ context.Context, path string) ([]*game, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(b); strings.EqualFold(filepath.Ext(path), ".csv") ||
+		path == stdio && !bytes.HasPrefix(trimmed, []byte("[")) && !bytes.HasPrefix(trimmed, []byte("{")) {
+		return readPrimeCSV(bytes.NewReader(b))
+	}
+	var items []map[string]any
+	if err = json.Unmarshal(b, &items); err != nil {
+		var wrapped struct {
+			Games []map[string]any `json:"games"`
+		}
+		if json.Unmarshal(b, &wrapped) != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		items = wrapped.Games
+	}
+	games := make([]*game, 0, len(items))
+	for _, item := range items {
+		lower := make(map[string]string, len(item))
+		for k, v := range item {
+			switch v := v.(type) {
+			case string:
+				lower[strings.ToLower(k)] = v
+			case float64: // like the year
+				lower[strings.ToLower(k)] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		if g := primeGame(func(key string) string { return lower[key] }); g != nil {
+			games = append(games, g)
+		}
+	}
+	return games, nil
+}
+
+func readPrimeCSV(r io.Reader) ([]*game, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, h := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	var games []*game
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return games, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		g := primeGame(func(key string) string {
+			if i := slices.Index(header, key); i > -1 && i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		})
+		if g != nil {
+			games = append(games, g)
+		}
+	}
+}
+
+// primeGame returns the game by the first non-empty name and logo fields, nil without a name.
+func primeGame(field func(key string) string) *game {
+	first := func(keys []string) string {
+		for _, k := range keys {
+			if v := strings.TrimSpace(field(k)); len(v) > 0 {
+				return v
+			}
+		}
+		return ""
+	}
+	name := first(primeNames)
+	if len(name) == 0 {
+		return nil
+	}
+	return &game{Name: name, Logo: first(primeLogos), Year: parseYear(first(primeYears))}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	itchStore = "itch.io"
+	itchKeys  = "https://itch.io/api/1/%s/my-owned-keys?page=%d"
+)
+
+// itchKey is the itch.io API key for the itch input, from https://itch.io/user/settings/api-keys.
+var itchKey string
+
+// itchPage is a page of the owned keys of the itch.io account.
+type itchPage struct {
+	OwnedKeys []struct {
+		Game struct {
+			Title    string `json:"title"`
+			URL      string `json:"url"`
+			CoverURL string `json:"cover_url"`
+		} `json:"game"`
+	} `json:"owned_keys"`
+	PerPage int      `json:"per_page"`
+	Errors  []string `json:"errors"`
+}
+
+// readItch lists the games owned on itch.io. The path is unused, the API key is from the -itch-key
+// flag or the ITCH_API_KEY environment variable. The itch page of the game is its fallback link.
+func readItch(ctx context.Context, _ string) ([]*game, error) {
+	key := itchKey
+	if len(key) == 0 {
+		key = os.Getenv("ITCH_API_KEY")
+	}
+	if len(key) == 0 {
+		return nil, errors.New("itch.io API key is missing, use -itch-key or ITCH_API_KEY")
+	}
+	var games []*game
+	for page := 1; ; page++ {
+		var p itchPage
+		if err := getItchPage(ctx, fmt.Sprintf(itchKeys, url.PathEscape(key), page), &p); err != nil {
+			return nil, err
+		}
+		if len(p.Errors) > 0 {
+			return nil, fmt.Errorf("itch.io API: %s", strings.Join(p.Errors, ", "))
+		}
+		for _, k := range p.OwnedKeys {
+			games = append(games, &game{Name: k.Game.Title, Logo: k.Game.CoverURL, Link: k.Game.URL, Source: itchStore})
+		}
+		if len(p.OwnedKeys) == 0 || len(p.OwnedKeys) < p.PerPage {
+			return games, nil
+		}
+	}
+}
+
+// getItchPage decodes the API response of the link to p. It's not cached, so new games show up.
+func getItchPage(ctx context.Context, link string, p *itchPage) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// the key is in the link
+		return errors.New("failed to get itch.io owned keys")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to get itch.io owned keys: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(p)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// pageText is the text of the HTML page in a language.
+type pageText struct {
+	// locale is the store locale of the card links.
+	locale                                 string
+	title, confidence, free                string
+	other, matched, otherStores, unmatched string
+	wishlist, locked, achievements         string
+	contents, empty                        string
+	games, editors, apps                   string
+	// the texts of the what's new page of diff
+	whatsNew, added, removed, renamed, noChanges string
+	// the titles of the theme and the view buttons of the page
+	dark, view string
+	// delisted is the badge of the cards gone from the store, by -check-delisted
+	delisted string
+}
+
+// pageTexts are the languages of the HTML page by -lang.
+var pageTexts = map[string]pageText{
+	"en": {"en-US", "My Games", "match confidence", "Free",
+		"Other", "Matched", "Other stores", "Unmatched",
+		"Wishlist", "Unavailable in your region", "achievements", "Contents", "No games yet",
+		"Games", "Editors", "Apps",
+		"What's new", "Added", "Removed", "Renamed", "No changes",
+		"Dark mode", "Grid or list", "Delisted"},
+	"de": {"de", "Meine Spiele", "Übereinstimmung", "Kostenlos",
+		"Sonstige", "Gefunden", "Andere Stores", "Nicht gefunden",
+		"Wunschliste", "In deiner Region nicht verfügbar", "Erfolge", "Inhalt", "Noch keine Spiele",
+		"Spiele", "Editoren", "Apps",
+		"Neuigkeiten", "Hinzugefügt", "Entfernt", "Umbenannt", "Keine Änderungen",
+		"Dunkler Modus", "Raster oder Liste", "Nicht mehr im Store"},
+	"es": {"es-ES", "Mis juegos", "coincidencia", "Gratis",
+		"Otros", "Encontrados", "Otras tiendas", "No encontrados",
+		"Lista de deseos", "No disponible en tu región", "logros", "Contenido", "Todavía no hay juegos",
+		"Juegos", "Editores", "Aplicaciones",
+		"Novedades", "Añadidos", "Eliminados", "Renombrados", "Sin cambios",
+		"Modo oscuro", "Cuadrícula o lista", "Retirado de la tienda"},
+	"fr": {"fr", "Mes jeux", "correspondance", "Gratuit",
+		"Autres", "Trouvés", "Autres boutiques", "Non trouvés",
+		"Liste de souhaits", "Indisponible dans votre région", "succès", "Sommaire", "Pas encore de jeux",
+		"Jeux", "Éditeurs", "Applications",
+		"Nouveautés", "Ajoutés", "Retirés", "Renommés", "Aucun changement",
+		"Mode sombre", "Grille ou liste", "Retiré de la boutique"},
+	"it": {"it", "I miei giochi", "corrispondenza", "Gratis",
+		"Altri", "Trovati", "Altri negozi", "Non trovati",
+		"Lista dei desideri", "Non disponibile nella tua regione", "obiettivi", "Indice", "Ancora nessun gioco",
+		"Giochi", "Editor", "App",
+		"Novità", "Aggiunti", "Rimossi", "Rinominati", "Nessuna modifica",
+		"Modalità scura", "Griglia o elenco", "Rimosso dal negozio"},
+	"pl": {"pl", "Moje gry", "dopasowanie", "Za darmo",
+		"Inne", "Znalezione", "Inne sklepy", "Nieznalezione",
+		"Lista życzeń", "Niedostępne w twoim regionie", "osiągnięcia", "Spis treści", "Jeszcze brak gier",
+		"Gry", "Edytory", "Aplikacje",
+		"Co nowego", "Dodane", "Usunięte", "Ze zmienioną nazwą", "Brak zmian",
+		"Tryb ciemny", "Siatka lub lista", "Wycofany ze sklepu"},
+	"pt": {"pt-BR", "Meus jogos", "correspondência", "Grátis",
+		"Outros", "Encontrados", "Outras lojas", "Não encontrados",
+		"Lista de desejos", "Indisponível na sua região", "conquistas", "Índice", "Ainda não há jogos",
+		"Jogos", "Editores", "Aplicativos",
+		"Novidades", "Adicionados", "Removidos", "Renomeados", "Nenhuma alteração",
+		"Modo escuro", "Grade ou lista", "Removido da loja"},
+	"ru": {"ru", "Мои игры", "совпадение", "Бесплатно",
+		"Другие", "Найдены", "Другие магазины", "Не найдены",
+		"Список желаемого", "Недоступно в вашем регионе", "достижения", "Содержание", "Пока нет игр",
+		"Игры", "Редакторы", "Приложения",
+		"Что нового", "Добавлены", "Удалены", "Переименованы", "Без изменений",
+		"Тёмная тема", "Сетка или список", "Снято с продажи"},
+	"ja": {"ja", "マイゲーム", "一致度", "無料",
+		"その他", "一致", "他のストア", "不一致",
+		"ウィッシュリスト", "お住まいの地域では利用できません", "実績", "目次", "まだゲームがありません",
+		"ゲーム", "エディター", "アプリ",
+		"新着", "追加", "削除", "名前の変更", "変更なし",
+		"ダークモード", "グリッドまたはリスト", "販売終了"},
+	"zh": {"zh-CN", "我的游戏", "匹配度", "免费",
+		"其他", "已匹配", "其他商店", "未匹配",
+		"愿望单", "在您所在的地区不可用", "成就", "目录", "还没有游戏",
+		"游戏", "编辑器", "应用",
+		"新内容", "新增", "已移除", "已重命名", "没有变化",
+		"深色模式", "网格或列表", "已下架"},
+}
+
+var (
+	// lang is the language of the HTML page, and text is its text.
+	lang = "en"
+	text = pageTexts["en"]
+	// localLinks tells to link the cards to the store in the language of the page, set by -lang.
+	localLinks bool
+)
+
+// setLang sets the language of the page, like de, or de-AT for German.
+func setLang(l string) error {
+	base, _, _ := strings.Cut(strings.ToLower(l), "-")
+	t, ok := pageTexts[base]
+	if !ok {
+		return fmt.Errorf("unknown language %q, use %s", l, strings.Join(slices.Sorted(maps.Keys(pageTexts)), ", "))
+	}
+	lang, text, localLinks = l, t, true
+	return nil
+}
+
+// pageHeader returns the start of the HTML page in its language.
+func pageHeader() string {
+	return titledHeader(text.title)
+}
+
+// titledHeader returns the start of an HTML page of the title in the language of the page.
+func titledHeader(title string) string {
+	return fmt.Sprintf(htmlHeader, lang, html.EscapeString(title), html.EscapeString(text.dark), html.EscapeString(text.view))
+}
+
+// localLink returns the store product link in the locale of the page language, other links as is.
+func localLink(link string) string {
+	if !localLinks || !epicmatch.IsProduct(link) {
+		return link
+	}
+	return epicmatch.CanonicalLink(link, text.locale)
+}
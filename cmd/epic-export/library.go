@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	libraryItems = "https://library-service.live.use1a.on.epicgames.com/library/api/public/items?includeMetadata=true"
+	catalogItems = "https://catalog-public-service-prod06.ol.epicgames.com/catalog/api/shared/namespace/%s/bulk/items" +
+		"?id=%s&includeDLCDetails=true&includeMainGameDetails=true&country=US&locale=en"
+	// unrealNamespace is the namespace of the Unreal Engine marketplace assets.
+	unrealNamespace = "ue"
+)
+
+// epicToken is the Epic OAuth access token for the library input, from the -epic-token flag,
+// EPIC_TOKEN or the stored login of legendary.
+var epicToken string
+
+// legendaryUser is the stored login of the legendary launcher.
+type legendaryUser struct {
+	AccessToken string `json:"access_token"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+// libraryPage is a page of the library items of the account.
+type libraryPage struct {
+	ResponseMetadata struct {
+		NextCursor string `json:"nextCursor"`
+	} `json:"responseMetadata"`
+	Records []struct {
+		Namespace     string `json:"namespace"`
+		CatalogItemID string `json:"catalogItemId"`
+	} `json:"records"`
+}
+
+// catalogItem is the catalog details of a library item.
+type catalogItem struct {
+	Title     string `json:"title"`
+	KeyImages []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"keyImages"`
+	// MainGameItem is set for DLCs.
+	MainGameItem *struct{} `json:"mainGameItem"`
+}
+
+// logoTypes are the key image types of the logo, by precedence.
+var logoTypes = []string{"DieselGameBoxTall", "DieselGameBox", "Thumbnail", "OfferImageTall"}
+
+// readLibrary lists the games of the Epic account by the library API. The path is the user.json
+// of legendary, its default place without a path, unless -epic-token or EPIC_TOKEN is set. DLCs and
+// Unreal Engine assets are left out.
+func readLibrary(ctx context.Context, path string) ([]*game, error) {
+	token, err := libraryToken(path)
+	if err != nil {
+		return nil, err
+	}
+	var games []*game
+	for cursor := ""; ; {
+		link := libraryItems
+		if len(cursor) > 0 {
+			link += "&cursor=" + url.QueryEscape(cursor)
+		}
+		var p libraryPage
+		if err = getEpicAPI(ctx, link, token, &p); err != nil {
+			return nil, fmt.Errorf("failed to get library: %w", err)
+		}
+		for _, r := range p.Records {
+			if r.Namespace == unrealNamespace || len(r.CatalogItemID) == 0 {
+				continue
+			}
+			items := map[string]catalogItem{}
+			link = fmt.Sprintf(catalogItems, url.PathEscape(r.Namespace), url.QueryEscape(r.CatalogItemID))
+			if err = getEpicAPI(ctx, link, token, &items); err != nil {
+				return nil, fmt.Errorf("failed to get catalog item %s: %w", r.CatalogItemID, err)
+			}
+			it, ok := items[r.CatalogItemID]
+			if !ok || it.MainGameItem != nil || len(it.Title) == 0 {
+				continue
+			}
+			games = append(games, &game{Name: it.Title, Logo: it.logo()})
+		}
+		if cursor = p.ResponseMetadata.NextCursor; len(cursor) == 0 || len(p.Records) == 0 {
+			return games, nil
+		}
+	}
+}
+
+// logo returns the best key image of the item for the logo.
+func (it *catalogItem) logo() string {
+	for _, t := range logoTypes {
+		for _, img := range it.KeyImages {
+			if img.Type == t {
+				return img.URL
+			}
+		}
+	}
+	return ""
+}
+
+// libraryToken returns the access token by the flag, the environment or the login of legendary in
+// this order. An expired login of legendary isn't refreshed, as that would log out legendary.
+func libraryToken(path string) (string, error) {
+	if len(epicToken) > 0 {
+		return epicToken, nil
+	}
+	if t := os.Getenv("EPIC_TOKEN"); len(t) > 0 {
+		return t, nil
+	}
+	if len(path) == 0 {
+		// legendary uses the same place on all systems
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if len(dir) == 0 {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".config")
+		}
+		path = filepath.Join(dir, "legendary", "user.json")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("no Epic login, use -epic-token, EPIC_TOKEN or log in with legendary: %w", err)
+	}
+	var u legendaryUser
+	if err = json.Unmarshal(b, &u); err != nil {
+		return "", fmt.Errorf("failed to decode legendary login %s: %w", path, err)
+	}
+	if len(u.AccessToken) == 0 {
+		return "", fmt.Errorf("no access token in legendary login %s", path)
+	}
+	if exp, err := time.Parse(time.RFC3339, u.ExpiresAt); err == nil && time.Now().After(exp) {
+		return "", errors.New("legendary login expired, refresh it by running: legendary status")
+	}
+	return u.AccessToken, nil
+}
+
+// getEpicAPI decodes the API response of the link with the bearer token to v. It's not cached, so
+// new games show up.
+func getEpicAPI(ctx context.Context, link, token string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("the Epic login is invalid or expired: %s", resp.Status)
+		}
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
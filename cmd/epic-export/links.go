@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// linkNoLocale is the -link-locale of the store links without a locale prefix, the store picking
+// the language of the visitor.
+const linkNoLocale = "none"
+
+var (
+	// linkLocale is the locale prefix of the written store links by -link-locale, the -locale by
+	// default.
+	linkLocale string
+	// followRedirects checks the product pages of the matches for their current slug, by
+	// -follow-redirects.
+	followRedirects bool
+)
+
+// followLink replaces the link of the result with the product page it ends up at, if asked for.
+func followLink(ctx context.Context, r *result) {
+	if !followRedirects || dryRun || !epicmatch.IsProduct(r.Link) || r.Locked {
+		return
+	}
+	link, err := matcher.FinalLink(ctx, r.Link)
+	if err != nil {
+		slog.Warn("failed to follow link", "game", r.Name, "stage", "enrich", "link", r.Link, "err", err)
+		addFailure(r.Name, "final link", err)
+		return
+	}
+	r.Link = link
+}
+
+// canonicalLink returns the link in its stable form by -link-locale, so the outputs of the runs
+// are the same. The links of region locked pages keep the locale they're available in.
+func canonicalLink(r *result) string {
+	if len(r.Link) == 0 || r.Locked {
+		return r.Link
+	}
+	locale := linkLocale
+	if locale == linkNoLocale {
+		locale = ""
+	}
+	return epicmatch.CanonicalLink(r.Link, locale)
+}
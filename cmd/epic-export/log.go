@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// setupLog sets the default logger writing to the terminal, and also to the file at path if not
+// empty. The returned file should be closed at exit.
+func setupLog(verbose bool, format, path string) (*os.File, error) {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if verbose {
+		opts.Level = slog.LevelDebug
+	}
+	var newHandler func(io.Writer) slog.Handler
+	switch format {
+	case "text":
+		newHandler = func(w io.Writer) slog.Handler { return slog.NewTextHandler(w, opts) }
+	case "json":
+		newHandler = func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, opts) }
+	default:
+		return nil, fmt.Errorf("unknown log format %q, use text or json", format)
+	}
+
+	h := teeHandler{newHandler(termWriter{})}
+	var f *os.File
+	if len(path) > 0 {
+		var err error
+		if f, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+			return nil, err
+		}
+		h = append(h, newHandler(f))
+	}
+	slog.SetDefault(slog.New(h))
+	return f, nil
+}
+
+// termWriter shows log lines in the log region of the terminal UI while it's running, otherwise
+// on stderr. The UI goroutine alone writes the terminal, so the lines are queued for it without
+// waiting: a slow terminal, like over SSH, drops lines instead of stalling the searches.
+type termWriter struct{}
+
+func (termWriter) Write(b []byte) (int, error) {
+	uiLogMtx.Lock()
+	defer uiLogMtx.Unlock()
+	if !uiRunning.Load() {
+		return os.Stderr.Write(b)
+	}
+	for _, l := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		select {
+		case uiLogs <- l:
+		default:
+			droppedLogs.Add(1)
+		}
+	}
+	return len(b), nil
+}
+
+// teeHandler sends log records to all of its handlers.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			if herr := h.Handle(ctx, r.Clone()); herr != nil {
+				err = herr
+			}
+		}
+	}
+	return err
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make(teeHandler, len(t))
+	for i, h := range t {
+		res[i] = h.WithAttrs(attrs)
+	}
+	return res
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	res := make(teeHandler, len(t))
+	for i, h := range t {
+		res[i] = h.WithGroup(name)
+	}
+	return res
+}
+
+// stage tags the further log lines of the game with its stage, like search or fuzzy, and the
+// stages of the retry pass with a retry/ prefix. A new stage is the progress of the game for the
+// watchdog of stalls.
+func (g *game) stage(name string) {
+	if g.retried {
+		name = "retry/" + name
+	}
+	log := slog.With("game", g.Name, "stage", name)
+	stallMtx.Lock()
+	defer stallMtx.Unlock()
+	g.log, g.lastBeat = log, time.Now()
+}
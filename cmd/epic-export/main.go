@@ -0,0 +1,1368 @@
+// Command epic-export exports a list of games from Epic Games to an HTML gallery or other formats,
+// finding the store page of each game automatically or with the help of the user.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+const (
+	skipItem = "Skip item"
+	noLink   = "No link"
+	typeLink = "Type link"
+	// refineSearch searches the store again by a typed query, like the name without its subtitle.
+	refineSearch = "Refine search"
+	schByImg     = "Search by logo"
+	resByImg     = "BY LOGO SEARCH"
+	// useLink and backToList confirm the typed link by the title of its page.
+	useLink    = "Write this link"
+	backToList = "Back to the list"
+)
+
+var (
+	writer *bufio.Writer
+	out    output
+
+	concurrency = 5
+	pageSize    = 40
+	matcher     *epicmatch.Client
+	// withPrices fetches the product page of matched games for the current price.
+	withPrices bool
+	// withMetadata fetches the product page of matched games for the developer, release date etc.
+	withMetadata bool
+	// autoAccept is the minimum confidence of the best search result to take it without asking.
+	autoAccept int
+	// prefer is the kind of the results of the same base game written instead of the picked one by
+	// -prefer, see prefers, empty for the picked one.
+	prefer string
+	// prefers are the values of -prefer.
+	prefers = []string{"", epicmatch.PreferBase, epicmatch.PreferEdition, epicmatch.PreferBundle}
+)
+
+type game struct {
+	Name string `json:"applicationName"`
+	Logo string `json:"logo"`
+	work *work
+
+	// isFuzzy is true for the second phase is a fuzzy matching and user-picking,
+	// in case of no exact match.
+	isFuzzy bool
+	// schdByImg means if search by logo was already run for this game.
+	schdByImg bool
+	// refined is the last query typed by refineSearch.
+	refined string
+	// slugVariant is the variant of the name whose slug is the product page, see ResolveVariant.
+	slugVariant string
+	// log tags the log lines with the game and its stage, see stage.
+	log *slog.Logger
+	// lastBeat is the time of the last progress of the game, see watchStalls.
+	lastBeat time.Time
+	// done is true if the game was processed before an interrupt.
+	done bool
+	// emitted is true once the result of the game is written, so a stalled enrich isn't retried.
+	emitted bool
+	// index is the position of the game in the input.
+	index int
+	// retrying is true while the game waits in the retry queue, retried after it's run again.
+	retrying, retried bool
+	// asking is true while the game waits for the user after the automatic phase.
+	asking bool
+	// Link is the page of the game in its source library if any, used without an Epic match.
+	Link string `json:"link,omitempty"`
+	// Source is the store name of Link.
+	Source string `json:"source,omitempty"`
+	// Sources are the launchers of the game for many inputs.
+	Sources []string `json:"sources,omitempty"`
+	// Year is the release year from the input if known, to tell apart the games of the same name.
+	Year int `json:"year,omitempty"`
+	// dup is true if another game of the input has the same name, but another release year.
+	dup bool
+	// Wishlist flags the game as wanted instead of owned, for -mode wishlist.
+	Wishlist bool `json:"wishlist,omitempty"`
+	// hashDist is the hash distance of the logo and the thumbnail of the search result by logo.
+	hashDist int
+	// picked is the decision of the candidates file for apply.
+	picked string
+	playStats
+}
+
+func main() {
+	defer exit()
+	var subcmd string
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case reviewCmd, applyCmd, versionCmd, selfUpdateCmd, serveAPICmd, renderCmd, diffCmd, calibrateCmd:
+			subcmd = os.Args[1]
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		}
+	}
+	review, applying, servingAPI := subcmd == reviewCmd, subcmd == applyCmd, subcmd == serveAPICmd
+	rendering, diffing, calibrating := subcmd == renderCmd, subcmd == diffCmd, subcmd == calibrateCmd
+	var input paths
+	flag.Var(&input, "i", "exported games file or directory path, can be repeated to merge them, "+
+		"with an optional input format prefix like prime:claimed.csv")
+	inputFormat := flag.String("input-format", "epic", "input file format: epic (authorized apps JSON), prime "+
+		"(Prime Gaming claimed games JSON or CSV), itch (owned games of the -itch-key account) or library (Epic "+
+		"library of the -epic-token account or of the legendary login)")
+	flag.StringVar(&epicToken, "epic-token", "", "Epic OAuth access token for -input-format library, EPIC_TOKEN or "+
+		"the login of legendary by default")
+	flag.StringVar(&itchKey, "itch-key", "", "itch.io API key for -input-format itch, ITCH_API_KEY by default")
+	outPath := flag.String("o", "", "output file path, its content depends on -format, - for stdout")
+	format := flag.String("format", "html", "output format: html, md, csv, json, obsidian (Markdown note) or opml")
+	tmplPath := flag.String("template", "", "html/template file of a game card, replacing -format, see README")
+	flag.StringVar(&ownedPath, "owned-report", "", "JSON file of the games owned on more than one store of several "+
+		"inputs, also printed at the end")
+	mode := flag.String("mode", modeLibrary, "library, or wishlist to also write the games flagged as wanted by the "+
+		"input and the unmatched ones to -wishlist, with their store search links and prices")
+	flag.StringVar(&wishlistPath, "wishlist", "wishlist.html", "HTML file, or JSON for .json, of the wishlist of -mode wishlist")
+	flag.StringVar(&failuresPath, "errors", "errors.json", "JSON file of the failed and skipped games of the run")
+	flag.StringVar(&candidatesPath, "candidates-out", "", "JSON file of the fuzzy games with their ranked search "+
+		"results to decide later by the apply subcommand, instead of asking")
+	flag.StringVar(&pendingPath, "pending", "pending.json", "file of the games left for review when there's no terminal to ask")
+	pageLang := flag.String("lang", "", "language of the html page title, sections and texts, its store locale is used "+
+		"for the card links: en, de, es, fr, it, ja, pl, pt, ru or zh")
+	groupBy := flag.String("group-by", "", "collapsible sections of the html output: source, letter, genre (with "+
+		"-metadata), type (product category) or matched")
+	order := flag.String("order", "input", "order of the written games: input, alpha, playtime (the most "+
+		"played first) or resolved (as soon as possible)")
+	flag.IntVar(&flushEvery, "flush", 10, "write a valid partial output after every this many results, 0 only at the end")
+	flag.IntVar(&batches.size, "batch-size", 0, "resolve the games by batches of this many, writing the results of "+
+		"each batch into its own file next to the output too, like games.batch-001.html, 0 resolves them all at once")
+	cacheTTL := flag.Duration("cache-ttl", 24*time.Hour, "maximum age of cached store responses, 0 disables the cache")
+	cacheDir := flag.String("cache-dir", epicmatch.DefaultCacheDir(), "directory of cached store responses")
+	flag.BoolVar(&useCatalog, "catalog-index", false, "download the titles of the whole store catalog once, and "+
+		"find the games among them locally before searching the store")
+	catalogTTL := flag.Duration("catalog-ttl", 7*24*time.Hour, "maximum age of the saved titles of -catalog-index")
+	flag.IntVar(&concurrency, "concurrency", concurrency, "number of games searched at the same time")
+	timeout := flag.Duration("timeout", 30*time.Second, "maximum time of a store request, 0 doesn't limit it")
+	deadline := flag.Duration("deadline", 0, "maximum time of the whole run, the unfinished games are left in -pending "+
+		"after it, 0 doesn't limit it")
+	maxMemory := flag.String("max-memory", "", "soft memory limit of the run like 512M, the garbage collector works "+
+		"harder near it and the response buffers kept for reuse take at most an eighth of it")
+	delay := flag.Duration("delay", time.Millisecond*300, "minimum delay between store requests")
+	breakAfter := flag.Int("break-after", 5, "number of Cloudflare challenges in a row pausing all store requests "+
+		"for the cooldown, 0 never pauses")
+	cooldown := flag.Duration("cooldown", 5*time.Minute, "pause of all store requests after -break-after challenges")
+	flag.DurationVar(&retryDelay, "retry-delay", retryDelay, "delay between store requests of retrying the games "+
+		"failed by Cloudflare or timeouts at the end of the run, 0 doesn't retry")
+	flag.IntVar(&pageSize, "page-size", pageSize, "number of store search results on a page")
+	maxResults := flag.Int("max-results", 0, "maximum number of store search results to rank, got by pages of "+
+		"-page-size until a result of the same name, one page by default")
+	locale := flag.String("locale", epicmatch.DefaultLocale, "store locale of the links, like de-DE")
+	flag.StringVar(&linkLocale, "link-locale", "", "locale prefix of the written store links, like de-DE, or none to "+
+		"leave it to the store, the -locale by default")
+	flag.BoolVar(&followRedirects, "follow-redirects", false, "check the product pages of the matches, writing the "+
+		"current slug of the renamed ones")
+	regionLocale := flag.String("region-locale", "", "store locale of trying again the product pages unavailable in "+
+		"the region, like en-US, linked in it if they're available there")
+	locales := flag.String("search-locales", "", "comma separated store locales also searched for localized names "+
+		"without a result of the same name, like ja,de-DE")
+	flag.StringVar(&translateURL, "translate", "", "LibreTranslate endpoint translating localized names to English "+
+		"for searching, like http://localhost:5000")
+	flag.StringVar(&translateKey, "translate-key", "", "API key of the -translate endpoint")
+	country := flag.String("country", "", "store country code for search results and prices, like DE, guessed by the store by default")
+	clearance := flag.String("cf-clearance", "", "cf_clearance cookie copied from the browser to skip Cloudflare challenges, "+
+		"use with -header of the same user agent")
+	fetcher := flag.String("fetcher", "curl", "fetcher of the store pages: curl, or chromedp for a headless Chrome "+
+		"or Chromium running their JavaScript")
+	browserPath := flag.String("browser-path", "", "executable of the headless browser of -fetcher chromedp, found "+
+		"on the system by default")
+	solver := flag.String("solver", "", "FlareSolverr endpoint to solve Cloudflare challenges, like http://localhost:8191/v1")
+	proxyURL := flag.String("proxy", "", "proxy of the store requests, like http://host:port or socks5://host:port")
+	proxyList := flag.String("proxy-list", "", "file of proxies, one per line, rotated per store request")
+	record := flag.String("record", "", "directory to save all store responses to, for -replay")
+	replay := flag.String("replay", "", "directory of responses saved by -record to use instead of the network")
+	giveawaySrc := flag.String("giveaways", "", "mark games given away free: epic for the current and upcoming ones, "+
+		"or a JSON file or link of the history, see README")
+	availability := flag.String("availability", "", "comma separated services to mark the matched games playable on "+
+		"by their public catalogs: geforce-now (streaming the copy of the store) and game-pass")
+	imgSearch := flag.String("img-search", "lens", "logo search backend: lens (Google Lens page), serpapi, bing or tineye")
+	imgDelay := flag.Duration("img-search-delay", time.Second, "minimum delay between logo search requests, "+
+		"independent of the store requests")
+	imgKey := flag.String("img-search-key", "", "API key of the serpapi, bing or tineye logo search")
+	similarity := flag.String("matcher", "all", "comma separated similarity steps of ranking the search results: romanize, fold, "+
+		"punct, editions, numerals, tokenset, all or levenshtein (none)")
+	flag.StringVar(&prefer, "prefer", "", "link written of the results of the same base game: base, edition or "+
+		"bundle, the picked one by default")
+	types := flag.String("types", "", "comma separated product categories searched in the store: games, editors, "+
+		"apps, all by default")
+	exclude := flag.String("exclude", "", "comma separated kinds of search results to drop: dlc, addons, editions, demos, soundtracks")
+	ignorePath := flag.String("ignore", "", "file of game names or globs like \"* Demo\" to leave out of the run, one per line")
+	aliasPath := flag.String("aliases", "", "YAML file of game names to Epic slugs or links, used before any search")
+	notesPath := flag.String("notes", "", "YAML file of game names to their tags, note and rating, written into the "+
+		"cards and the JSON output")
+	flag.StringVar(&contribPath, "contrib", "", "JSON file of the game names to the Epic slugs matched in the run, "+
+		"to share with others")
+	var importMappings paths
+	flag.Var(&importMappings, "import-mappings", "JSON file or link of game names to Epic slugs shared by others, "+
+		"used before any search, can be repeated")
+	dbPath := flag.String("db", "", "match database path to skip games resolved in previous runs")
+	update := flag.Bool("update", false, "skip the games already in the -o output, and add the new ones to it")
+	flag.BoolVar(&checkDelisted, "check-delisted", false, "check the product pages of the games already in the "+
+		"-o output of -update, and mark the gone ones as delisted with a Wayback Machine link, html and json only")
+	flag.StringVar(&hookResolved, "hook-resolved", "", "shell command run for each game with a link, "+
+		"with its result JSON on stdin")
+	flag.StringVar(&hookUnresolved, "hook-unresolved", "", "shell command run for each game without a link or "+
+		"skipped, with its result JSON on stdin")
+	flag.StringVar(&feedPath, "feed", "", "RSS file, or JSON Feed for .json, of the games newly matched by -update")
+	flag.StringVar(&manifestPath, "manifest", "", "JSON file of how the output was made: the digests of the inputs "+
+		"and the output, the version, the times and the result of each game, for the render subcommand")
+	flag.BoolVar(&failOnUnresolved, "fail-on-unresolved", false, "exit with 2 if any game is left for review or "+
+		"failed, for the wrappers like cron jobs")
+	flag.DurationVar(&stallAfter, "stall-after", stallAfter, "report the games without progress for this long, "+
+		"like a hung request, 0 disables it")
+	flag.BoolVar(&stallKill, "stall-kill", false, "cancel the games stalled by -stall-after, and retry them after "+
+		"the main pass")
+	flag.BoolVar(&rebuild, "rebuild", false, "resolve all games again, overwriting the match database")
+	flag.BoolVar(&dryRun, "dry-run", false, "print a match quality report instead of asking and writing the output, "+
+		"in JSON with -format json")
+	flag.IntVar(&autoAccept, "auto-accept-threshold", 0, "take the best search result without asking if its confidence "+
+		"is at least this (1-100), 0 always asks")
+	flag.IntVar(&hashDistance, "hash-distance", 0, "take the search result without asking if its thumbnail differs "+
+		"from the source logo by at most this many bits of 64 of their image hashes, like 6, 0 disables it")
+	flag.BoolVar(&explainMatches, "explain", false, "record why each game matched, like the exact name or the "+
+		"Levenshtein distance, in the JSON output and as the data-explain attribute of the HTML cards")
+	flag.BoolVar(&withPrices, "prices", false, "add current prices and discounts of matched games from their product pages")
+	flag.BoolVar(&withMetadata, "metadata", false, "add developer, publisher, release date and genres of matched games "+
+		"from their product pages")
+	flag.StringVar(&images, "images", images, "images of the HTML cards: logo (of the input), hero (the landscape "+
+		"artwork of the product page, falling back to the logo) or both")
+	fallbackList := flag.String("fallback-stores", "", "comma separated stores to search when there's no match on Epic: steam, gog")
+	flag.StringVar(&preview, "preview", preview, "renderer of the logos and thumbnails in the terminal: blocks, kitty, "+
+		"sixel or auto (by the terminal)")
+	flag.StringVar(&exportTo, "export", "", "also push the results to notion or airtable, see the flags below")
+	flag.StringVar(&notionDB, "notion-db", "", "ID of the Notion database of -export notion")
+	flag.StringVar(&notionToken, "notion-token", "", "Notion integration secret of -export notion, NOTION_TOKEN by default")
+	flag.StringVar(&airtableBase, "airtable-base", "", "ID of the Airtable base of -export airtable")
+	flag.StringVar(&airtableTable, "airtable-table", "Games", "name or ID of the table of -export airtable")
+	flag.StringVar(&airtableToken, "airtable-token", "", "Airtable personal access token of -export airtable, "+
+		"AIRTABLE_TOKEN by default")
+	flag.IntVar(&apiQueue, "api-queue", apiQueue, "maximum number of jobs waiting in the queue of serve-api")
+	flag.IntVar(&apiRate, "api-rate", apiRate, "jobs a client can post to serve-api in a minute, 0 doesn't limit them")
+	serveAddr := flag.String("serve", "", "pick the matches in the browser on this address, like :8080, instead of the terminal")
+	pushgateway := flag.String("pushgateway", "", "Prometheus pushgateway address to push the stats of the run to, "+
+		"like http://localhost:9091")
+	flag.StringVar(&statsEndpoint, "stats-endpoint", "", "opt in to posting the anonymous counts of the run by "+
+		"match method and failure to this http(s) address, without any names or links")
+	verbose := flag.Bool("v", false, "verbose logging, including expected misses")
+	logFile := flag.String("log-file", "", "also append logs to this file")
+	logFormat := flag.String("log-format", "text", "log format: text or json")
+	reqHeaders := headers{}
+	flag.Var(reqHeaders, "header", `extra store request header in "name: value" format, can be repeated`)
+	watchInputs := flag.Bool("watch", false, "export again after every change of the input files, needs -db to "+
+		"resolve only the new and changed games")
+	configPath := flag.String("config", "", "YAML config file of flag values, overridden by the command line (default "+
+		defaultConfig()+")")
+	flag.Parse()
+	// the flags of a render are the ones of the manifest, unless given here
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	// interrupts stop the workers, but let the finished results to be written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopRun = stop
+	must(loadConfig(*configPath), "config")
+	lf, err := setupLog(*verbose, *logFormat, *logFile)
+	must(err, "log setup")
+	if lf != nil {
+		defer lf.Close()
+	}
+	switch subcmd {
+	case versionCmd:
+		printVersion(ctx)
+		return
+	case selfUpdateCmd:
+		if err = selfUpdate(ctx); err != nil {
+			fmt.Println("self-update failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if review {
+		// epic-export review [flags] pending.json
+		if len(input) == 0 {
+			input = flag.Args()
+		}
+		*inputFormat = "epic"
+		if len(*tmplPath) > 0 || dryRun {
+			fmt.Println("review can't merge into a -template output or do a dry run")
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+	if applying {
+		// epic-export apply [flags] candidates.json
+		if len(input) == 0 {
+			input = flag.Args()
+		}
+		if len(input) != 1 || len(*tmplPath) > 0 || dryRun || *update || *watchInputs {
+			fmt.Println("apply needs a single candidates file, and can't merge into a -template output, " +
+				"do a dry run, update or watch")
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+	var rendered *manifest
+	if rendering {
+		// epic-export render [flags] manifest.json
+		if len(input) == 0 {
+			input = flag.Args()
+		}
+		if len(input) != 1 || dryRun || *update || *watchInputs || review {
+			fmt.Println("render needs a single manifest, and can't do a dry run, update or watch")
+			flag.Usage()
+			os.Exit(1)
+		}
+		rendered, err = readManifest(input[0], given)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to read manifest:", err)
+			os.Exit(1)
+		}
+	}
+	if diffing {
+		// epic-export diff [flags] old.json new.json
+		if len(input) == 0 {
+			input = flag.Args()
+		}
+		if len(input) != 2 || *watchInputs {
+			fmt.Println("diff needs the old and the new export, and can't watch")
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+	if calibrating {
+		// epic-export calibrate [flags] [name...]
+		if *watchInputs {
+			fmt.Println("calibrate can't watch")
+			flag.Usage()
+			os.Exit(1)
+		}
+		// every probe is a store request
+		*cacheTTL = 0
+	}
+	var apiListen string
+	if servingAPI {
+		// epic-export serve-api [flags] [address]
+		if flag.NArg() > 1 || *watchInputs || len(*serveAddr) > 0 || apiQueue <= 0 || apiRate < 0 {
+			fmt.Println("serve-api takes a single address to listen on, it can't watch or serve the web UI, " +
+				"api-queue must be positive and api-rate not negative")
+			flag.Usage()
+			os.Exit(1)
+		}
+		if apiListen = flag.Arg(0); len(apiListen) == 0 {
+			apiListen = apiAddr
+		}
+	}
+	if len(statsEndpoint) > 0 && !strings.HasPrefix(statsEndpoint, "http://") && !strings.HasPrefix(statsEndpoint, "https://") {
+		fmt.Println("stats-endpoint must be an http or https address")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if len(candidatesPath) > 0 && dryRun {
+		fmt.Println("candidates-out can't be used for a dry run")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *update && len(*tmplPath) > 0 {
+		fmt.Println("update can't read the games of a -template output")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if checkDelisted && (!*update || writtenLinks[*format] == nil || len(*tmplPath) > 0) {
+		fmt.Println("check-delisted needs -update of an html or json output")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if len(feedPath) > 0 && !*update {
+		fmt.Println("feed needs -update")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if batches.size < 0 {
+		fmt.Println("batch-size can't be negative")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if batches.size > 0 && *outPath == stdio {
+		fmt.Println("batch-size can't write the batch files of stdout")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *outPath == stdio && (review || applying || *update) {
+		fmt.Println("review, apply and update can't merge into stdout")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *inputFormat != "itch" && *inputFormat != "library" && len(input) == 0 && !servingAPI && !diffing && !calibrating {
+		mustString("", "exported games file path")
+	}
+	if len(input) == 0 {
+		input = paths{""}
+	}
+	if len(ownedPath) > 0 && len(input) < 2 {
+		fmt.Println("owned report needs several inputs")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if !dryRun && !servingAPI && !diffing && !calibrating {
+		mustString(*outPath, "result file path")
+	}
+	mustPositive(concurrency, "concurrency")
+	mustPositive(pageSize, "page size")
+	if *timeout < 0 || *deadline < 0 || stallAfter < 0 {
+		fmt.Println("timeout, deadline and stall-after must not be negative")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if len(*maxMemory) > 0 {
+		n, err := parseSize(*maxMemory)
+		if err != nil {
+			fmt.Println(err)
+			flag.Usage()
+			os.Exit(1)
+		}
+		limitMemory(n)
+	}
+	if *maxResults < 0 {
+		fmt.Println("max results must not be negative")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if !slices.Contains(prefers, prefer) {
+		fmt.Printf("prefer must be one of %s\n", strings.Join(prefers[1:], ", "))
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *breakAfter < 0 || *cooldown < 0 {
+		fmt.Println("break after and cooldown must not be negative")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if flushEvery < 0 {
+		fmt.Println("flush must not be negative")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *mode != modeLibrary && *mode != modeWishlist {
+		fmt.Println("mode must be library or wishlist")
+		flag.Usage()
+		os.Exit(1)
+	}
+	wishMode = *mode == modeWishlist
+	if *fetcher != "curl" && *fetcher != "chromedp" {
+		fmt.Println("fetcher must be curl or chromedp")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if !slices.Contains(orders, *order) {
+		fmt.Printf("order must be one of %s\n", strings.Join(orders, ", "))
+		flag.Usage()
+		os.Exit(1)
+	}
+	if len(*pageLang) > 0 {
+		if err := setLang(*pageLang); err != nil {
+			fmt.Println(err)
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+	if len(linkLocale) == 0 {
+		linkLocale = *locale
+	} else {
+		// the asked locale of the links is kept by any -lang
+		localLinks = false
+	}
+	if err := parseGroupBy(*groupBy); err != nil {
+		fmt.Println(err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if grouper != nil && (*format != "html" || len(*tmplPath) > 0 || *order == "resolved") {
+		fmt.Println("group-by needs the html format in input, alpha or playtime order")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if preview == "auto" {
+		preview = detectPreview()
+	} else if _, ok := previews[preview]; !ok {
+		fmt.Println("preview must be blocks, kitty, sixel or auto")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if images != imagesLogo && images != imagesHero && images != imagesBoth {
+		fmt.Println("images must be logo, hero or both")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if hashDistance < 0 || hashDistance > 64 {
+		fmt.Println("hash distance must be between 0 and 64")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if autoAccept < 0 || autoAccept > 100 {
+		fmt.Println("auto-accept threshold must be between 0 and 100")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *watchInputs {
+		if len(*dbPath) == 0 {
+			fmt.Println("watch needs -db to resolve only the new and changed games")
+			flag.Usage()
+			os.Exit(1)
+		}
+		must(watch(ctx, review, input), "watch")
+		return
+	}
+	must(checkExport(), "export")
+	must(parseFallbacks(*fallbackList), "fallback stores")
+	must(epicmatch.CheckImageSearch(*imgSearch), "logo search")
+	sim, err := epicmatch.ParseSimilarity(*similarity)
+	must(err, "matcher")
+	if sim.TokenSet {
+		rankName = "token set rank"
+	}
+	kinds, err := epicmatch.ParseKinds(*exclude)
+	must(err, "exclude")
+	productTypes, err := epicmatch.ParseTypes(*types)
+	must(err, "types")
+	services, err = parseServices(*availability)
+	must(err, "availability")
+	var proxyURLs []string
+	if len(*proxyList) > 0 {
+		proxyURLs, err = epicmatch.LoadProxies(*proxyList)
+		must(err, "proxy list")
+	}
+	if len(*proxyURL) > 0 {
+		must(epicmatch.CheckProxy(*proxyURL), "proxy")
+		proxyURLs = append(proxyURLs, *proxyURL)
+	}
+	matcher = epicmatch.New(epicmatch.Config{Delay: *delay, BreakAfter: *breakAfter, Cooldown: *cooldown, Timeout: *timeout, PageSize: pageSize, MaxResults: *maxResults,
+		CacheDir: *cacheDir, CacheTTL: *cacheTTL, Headers: reqHeaders, Locale: *locale, Locales: parseLocales(*locales),
+		RegionLocale: *regionLocale, Country: *country, Clearance: *clearance, Solver: *solver, Record: *record, Replay: *replay,
+		ImageSearch: *imgSearch, ImageSearchKey: *imgKey, ImageDelay: *imgDelay, Similarity: sim, Exclude: kinds, Types: productTypes, Proxies: proxyURLs,
+		Browser: *fetcher == "chromedp", BrowserPath: *browserPath, CatalogTTL: *catalogTTL})
+	defer matcher.Close()
+	if calibrating {
+		must(calibrate(ctx, flag.Args(), *configPath, *delay), "calibrate")
+		return
+	}
+	if diffing {
+		must(diffExports(ctx, input, *inputFormat, *outPath), "diff")
+		return
+	}
+	if useCatalog {
+		loadCatalog(ctx)
+	}
+	if len(services) > 0 && !dryRun {
+		loadServices(ctx)
+	}
+	if len(*giveawaySrc) > 0 {
+		must(loadGiveaways(ctx, *giveawaySrc), "giveaways")
+	}
+	if len(*aliasPath) > 0 {
+		must(loadAliases(*aliasPath), "aliases")
+	}
+	if len(*notesPath) > 0 {
+		must(loadNotes(*notesPath), "notes")
+	}
+	if len(*ignorePath) > 0 {
+		must(loadIgnore(*ignorePath), "ignore list")
+	}
+	if len(importMappings) > 0 {
+		must(loadMappings(ctx, importMappings), "import mappings")
+	}
+	if len(*dbPath) > 0 {
+		must(openDB(*dbPath), "match database")
+		defer db.Close()
+	}
+	if servingAPI {
+		must(serveAPI(ctx, apiListen), "API")
+		return
+	}
+
+	var games []*game
+	switch {
+	case applying:
+		games, err = readCandidates(input[0])
+	case rendering:
+		games = rendered.games()
+	default:
+		games, err = readInputs(ctx, input, *inputFormat)
+	}
+	if err != nil {
+		// a broken input is the user's to fix, not a crash
+		fmt.Fprintln(os.Stderr, "failed to read games file:", err)
+		os.Exit(1)
+	}
+	if len(games) == 0 {
+		fmt.Fprintln(os.Stderr, "there are no games in the input")
+	}
+	if !applying && !rendering {
+		games = dropIgnored(games)
+	}
+	merge := review || applying
+	if *update {
+		written, err := writtenNames(*outPath, *format)
+		if !errors.Is(err, fs.ErrNotExist) {
+			must(err, "read result file to update")
+			merge = true
+		}
+		all := len(games)
+		games = slices.DeleteFunc(games, func(g *game) bool { return written[normName(g.Name)] })
+		slog.Info("updating the output", "new", len(games), "written", all-len(games))
+		if checkDelisted && merge {
+			must(findDelisted(ctx, *outPath, *format), "check delisted games")
+		}
+	}
+
+	if dryRun {
+		defer func() {
+			must(rep.write(os.Stdout, *format == "json"), "write report")
+		}()
+	} else {
+		var fo *os.File
+		var items bool
+		var toc string
+		switch {
+		case *outPath == stdio:
+			fo = os.Stdout
+		default:
+			// the previous output is replaced only by a complete one
+			fo, err = os.CreateTemp(filepath.Dir(*outPath), filepath.Base(*outPath)+".*.tmp")
+			must(err, "create result file")
+			if merge {
+				items, toc, err = copyMerged(fo, *outPath, *format)
+				must(err, "read result file to merge")
+			}
+			outFile = fo
+		}
+		defer fo.Close()
+		// big enough to keep the results between checkpoints, so the file stays valid
+		writer = bufio.NewWriterSize(fo, 1<<16)
+		newOut := func(w *bufio.Writer) (output, error) {
+			if len(*tmplPath) > 0 {
+				return newTemplateOutput(*tmplPath, w)
+			}
+			return newOutput(*format, w)
+		}
+		out, err = newOut(writer)
+		must(err, "output format")
+		if batches.size > 0 {
+			batches.path, batches.order, batches.newOutput = *outPath, *order, newOut
+		}
+		if !merge {
+			out.begin()
+		} else if o, ok := out.(*jsonOutput); ok && items {
+			o.count = 1
+		} else if o, ok := out.(*htmlOutput); ok {
+			o.keep(toc)
+		}
+		results = make(chan *result, concurrency)
+		written := make(chan struct{})
+		go writeResults(*order, written)
+		startHooks()
+		defer func() {
+			close(results)
+			<-written
+			stopHooks()
+			out.end()
+			must(writer.Flush(), "write result file")
+			if outFile != nil {
+				// a paused run continues by the review of the pending file, merged into this output
+				must(replaceOutput(*outPath, merge, ctx.Err() != nil && !paused()), "replace result file")
+			}
+			if len(manifestPath) > 0 {
+				must(writeManifest(*outPath, *order), "write manifest")
+			}
+			if len(feedPath) > 0 {
+				if err := writeFeed(); err != nil {
+					slog.Error("failed to write feed", "err", err)
+				}
+			}
+			if len(exportTo) > 0 {
+				// the results of an interrupted run are exported too, for a limited time
+				ectx, cancel := ctx, context.CancelFunc(func() {})
+				if ctx.Err() != nil {
+					ectx, cancel = context.WithTimeout(context.WithoutCancel(ctx), exportGrace)
+				}
+				if err := export(ectx); err != nil {
+					slog.Error("failed to export results", "err", err)
+				}
+				cancel()
+			}
+		}()
+	}
+
+	tokens := make(chan *work, concurrency)
+	for range concurrency {
+		var work work
+		work.items = make([]epicmatch.Match, 0, pageSize)
+		work.display = make([]string, 0, pageSize+2) // skip texts
+		tokens <- &work
+	}
+
+	done := make(chan struct{})
+	if len(*serveAddr) > 0 && !dryRun {
+		must(serve(ctx, *serveAddr, done), "web UI")
+	}
+	started = time.Now()
+	if *deadline > 0 {
+		go pauseAt(*deadline, done)
+	}
+	if stallAfter > 0 {
+		go watchStalls(ctx)
+	}
+	go func() {
+		defer close(done)
+		for gi, g := range games {
+			games[gi].Name = strings.TrimSpace(g.Name)
+			g.index = gi
+			g.stage("input")
+		}
+		if rendering {
+			rendered.render()
+			return
+		}
+		if applying {
+			for _, g := range games {
+				g.stage("apply")
+				g.apply(ctx)
+				g.done = ctx.Err() == nil
+				uiProgress()
+			}
+			return
+		}
+		runBatches(ctx, games, tokens)
+		retryPass(ctx, tokens)
+		decidePass(ctx)
+	}()
+	runUI(ctx, stop, len(games), done)
+	<-done
+	redos.Wait()
+	emitSkips()
+	if paused() {
+		pendRest(games)
+	}
+	summary(games)
+	if !dryRun {
+		must(writePending(), "write pending games")
+	}
+	if len(candidatesPath) > 0 {
+		must(writeCandidates(), "write candidates")
+	}
+	if len(contribPath) > 0 && !dryRun {
+		must(writeContrib(), "write contributed mappings")
+	}
+	must(writeFailures(os.Stderr), "write failures")
+	if wishMode && !dryRun {
+		must(writeWishlist(*order), "write wishlist")
+	}
+	if len(ownedPath) > 0 {
+		must(writeOwned(os.Stderr), "write owned report")
+	}
+	must(writeStats(os.Stderr), "write stats")
+	if len(*pushgateway) > 0 {
+		// the run is interrupted maybe, but the stats are still worth it
+		pushCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := pushStats(pushCtx, *pushgateway); err != nil {
+			slog.Error("failed to push stats", "err", err)
+		}
+	}
+	if len(statsEndpoint) > 0 {
+		postCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := postUsage(postCtx, statsEndpoint, len(games)); err != nil {
+			slog.Error("failed to post usage stats", "err", err)
+		}
+	}
+	exitCode = outcome(ctx, games)
+}
+
+// runPass resolves the games concurrently by the work tokens, calling processed after each one.
+func runPass(ctx context.Context, games []*game, tokens chan *work, processed func()) {
+	var wg sync.WaitGroup
+	for _, g := range games {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var work *work
+			select {
+			case work = <-tokens:
+			case <-ctx.Done():
+				return
+			}
+			gctx, cancel := context.WithCancelCause(ctx)
+			unwatch := watchGame(g, cancel)
+			g.resolve(epicmatch.WithProgress(gctx, g.beat), work)
+			unwatch()
+			// a game stalled while enriching its result is emitted already
+			stalled := errors.Is(context.Cause(gctx), errStalled) && !g.emitted
+			cancel(nil)
+			tokens <- work
+			if stalled && ctx.Err() == nil {
+				g.requeueStalled()
+			}
+			g.done = ctx.Err() == nil && !g.retrying && !g.asking
+			processed()
+		}()
+	}
+	wg.Wait()
+}
+
+// summary logs the number of processed games, and lists the ignored and the delisted ones, and
+// the pending ones after an interrupt.
+func summary(games []*game) {
+	var pending []string
+	for _, g := range games {
+		if !g.done {
+			pending = append(pending, g.Name)
+		}
+	}
+	slog.Info("done", "completed", len(games)-len(pending), "pending", len(pending), "ignored", len(ignored),
+		"delisted", len(delisted))
+	if len(ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "ignored games:\n  %s\n", strings.Join(ignored, "\n  "))
+	}
+	if len(delisted) > 0 {
+		fmt.Fprintf(os.Stderr, "delisted games:\n  %s\n", strings.Join(slices.Sorted(maps.Values(delisted)), "\n  "))
+	}
+	if len(pending) > 0 {
+		fmt.Fprintf(os.Stderr, "pending games:\n  %s\n", strings.Join(pending, "\n  "))
+	}
+}
+
+// resolve finds the link of the game, by the given work token.
+func (g *game) resolve(ctx context.Context, work *work) {
+	g.stage("exact")
+	if link, ok := aliasLink(g.Name); ok {
+		g.write(ctx, methodAlias, link, 100)
+		return
+	}
+	if r, ok := dbGet(g.dbKey()); ok {
+		countStored()
+		if r.Method != methodSkip {
+			r.Logo, r.index, r.Sources, r.wishlist, r.playStats = g.Logo, g.index, g.Sources, g.Wishlist, g.playStats
+			r.Notes = noteOf(g.Name)
+			r.Explain = g.storedExplain(r)
+			enrich(ctx, r)
+			emit(r)
+			g.emitted = true
+		}
+		return
+	}
+	if link, ok := mappingLink(g.Name); ok && !g.dup {
+		g.write(ctx, methodMapping, link, 100)
+		return
+	}
+
+	var err error
+	// a title of the catalog index is found by the search without requests
+	if !inCatalog(g.Name) {
+		link, variant, err := matcher.ResolveVariant(ctx, g.Name)
+		locked := errors.Is(err, epicmatch.ErrRegionLocked)
+		if (err == nil || locked) && (!g.dup || g.released(ctx, link) == g.Year) {
+			if locked {
+				g.log.Warn("product page is unavailable in the region", "link", link)
+			}
+			if g.slugVariant = variant; len(variant) > 0 {
+				g.log.Debug("product page by a variant of the name", "variant", variant, "link", link)
+			}
+			g.save(ctx, &result{Name: g.Name, Link: link, Confidence: 100, Logo: g.Logo, Method: methodSlug, Locked: locked})
+			return
+		}
+		g.log.Debug("no product page by name", "err", err)
+	}
+
+	g.work = work
+	g.stage("search")
+	if err = g.search(ctx); err == nil || ctx.Err() != nil {
+		return
+	}
+	noExact := errors.Is(err, errNoExact)
+	if noExact {
+		g.log.Info("no exact search match", "results", len(g.work.items))
+	} else {
+		g.log.Info("no exact search match", "err", err)
+	}
+	if g.searchTranslated(ctx) || g.searchCollection(ctx) {
+		return
+	}
+	if noExact {
+		// choosing from the results of the full name
+		g.stage("search")
+		if err = g.choice(ctx, nil); err != nil && ctx.Err() == nil {
+			g.log.Error("search failed", "err", err)
+			addFailure(g.Name, "search", err)
+		}
+		return
+	}
+	g.isFuzzy = true
+	g.stage("fuzzy")
+	if err = g.search(ctx); err != nil && ctx.Err() == nil {
+		g.log.Error("search failed", "err", err)
+		addFailure(g.Name, "search", err)
+	}
+}
+
+// work contains logic for handling game search. It also works as a token for running only some
+// concurrent queries so that epicgames website doesn't block querying.
+type work struct {
+	items   []epicmatch.Match
+	display []string
+}
+
+// best returns the search result with the highest confidence, nil without any.
+func (w *work) best() *epicmatch.Match {
+	var b *epicmatch.Match
+	for i, m := range w.items {
+		if b == nil || m.Confidence > b.Confidence {
+			b = &w.items[i]
+		}
+	}
+	return b
+}
+
+// add appends the matches to the choices, editions indented under their base game.
+func (w *work) add(matches ...epicmatch.Match) {
+	for _, m := range matches {
+		var indent string
+		if n := len(w.items); n > 0 && len(m.Name) > 0 && m.Kind() == epicmatch.KindEdition && w.items[n-1].Base() == m.Base() {
+			indent = "  └ "
+		}
+		w.items = append(w.items, m)
+		switch {
+		case len(m.Name) == 0:
+			w.display = append(w.display, fmt.Sprintf("%s; %s", resByImg, m.Link))
+		case len(m.Store) > 0:
+			w.display = append(w.display, fmt.Sprintf("%s%s; %s; %s", indent, strings.ToUpper(m.Store), m.Name, m.Link))
+		default:
+			w.display = append(w.display, fmt.Sprintf("%s%s; %s", indent, m.Name, m.Link))
+		}
+	}
+}
+
+// clone returns a copy of the game with its own search results, to be used after releasing the work
+// token.
+func (g *game) clone() *game {
+	c := *g
+	items := g.work.items
+	c.work = &work{
+		items:   slices.Clone(items),
+		display: slices.Clone(g.work.display[:len(items)]),
+	}
+	return &c
+}
+
+// errNoExact is returned by the search by the full name with results, but none of the same name.
+var errNoExact = errors.New("no exact search match")
+
+// search processes the whole search for a given "app" game. The search by the full name returns
+// errNoExact with its results kept as the choices if none is of the same name.
+func (g *game) search(ctx context.Context) error {
+	name := g.Name
+	var err error
+	if g.isFuzzy {
+		name, err = epicmatch.ShortName(g.Name)
+		if err != nil {
+			g.log.Debug("using full name for fuzzy search", "err", err)
+			name = g.Name
+		}
+	}
+	work := g.work
+	work.items = work.items[:0]
+	work.display = work.display[:0]
+
+	matches, err := g.storeSearch(ctx, name)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if !g.isFuzzy {
+		exact := slices.DeleteFunc(slices.Clone(matches), func(m epicmatch.Match) bool { return !epicmatch.SameName(m.Name, name) })
+		if len(exact) > 1 || len(exact) > 0 && g.dup {
+			if m := g.byYear(ctx, exact); m != nil {
+				m.Confidence = 100
+				g.writeMatch(ctx, methodYear, m)
+				return nil
+			}
+		}
+		if len(exact) > 0 && !g.dup {
+			work.add(matches...)
+			exact[0].Confidence = 100
+			g.writeMatch(ctx, methodSearch, &exact[0])
+			return nil
+		}
+	}
+	work.add(matches...)
+	if !g.isFuzzy && err == nil {
+		// the results are the choices after the searches by translations and components
+		return errNoExact
+	}
+	// no exact match, pick
+	return g.choice(ctx, err)
+}
+
+// choice handles previous error and initiates choosing from the search result list.
+func (g *game) choice(ctx context.Context, err error) error {
+	if err != nil && g.retry(err) {
+		return nil
+	}
+	if err != nil {
+		if !g.isFuzzy {
+			return err
+		}
+		g.log.Warn("fuzzy search failed", "err", err)
+		addFailure(g.Name, "fuzzy search", err)
+	}
+	work := g.work
+	if len(work.display) == 0 && g.searchFallback(ctx) {
+		return nil
+	}
+	if len(g.Link) > 0 {
+		// the source library page without any match, or as the last choice, never auto-accepted
+		m := epicmatch.Match{Name: g.Name, Link: g.Link, Store: g.Source, Image: g.Logo}
+		if len(work.display) == 0 {
+			m.Confidence = 100
+			g.writeMatch(ctx, methodSource, &m)
+			return nil
+		}
+		work.add(m)
+	}
+	if len(work.display) == 0 {
+		if err = g.searchByImg(ctx); err != nil {
+			g.log.Warn("logo search failed", "err", err)
+			addFailure(g.Name, "logo search", err)
+		}
+	}
+	return g.pick(ctx)
+}
+
+// pick takes the search result matching the "app" by its release year, logo or confidence, or asks
+// the user to choose one after the automatic phase.
+func (g *game) pick(ctx context.Context) error {
+	if m := g.byYear(ctx, g.work.items); m != nil {
+		g.writeMatch(ctx, methodYear, m)
+		return nil
+	}
+	if m := g.byLogoHash(ctx); m != nil {
+		g.writeMatch(ctx, methodHash, m)
+		return nil
+	}
+	if m := g.work.best(); m != nil && autoAccept > 0 && m.Confidence >= autoAccept {
+		g.log.Info("auto-accepted", "match", m.Name, "confidence", m.Confidence)
+		g.writeMatch(ctx, methodAuto, m)
+		return nil
+	}
+	if dryRun {
+		rep.addFuzzy(g)
+		return nil
+	}
+	if len(candidatesPath) > 0 {
+		addCandidates(g)
+		return nil
+	}
+	if !deciding.Load() {
+		g.askLater()
+		return nil
+	}
+	return g.decide(ctx)
+}
+
+// decide asks the user to choose from the search results of the game.
+func (g *game) decide(ctx context.Context) error {
+	work := g.work
+	if !g.schdByImg {
+		work.display = append(work.display, schByImg)
+	}
+	work.display = append(work.display, refineSearch, noLink, typeLink, skipItem, acceptAll, skipAll, pauseRun)
+
+	var ans answer
+	var err error
+	if ans.choice = sessionChoice(); len(ans.choice) == 0 {
+		images := make([]string, len(work.items))
+		for i, m := range work.items {
+			images[i] = m.Image
+		}
+		ans, err = ask(ctx, &prompt{
+			name:    g.Name,
+			title:   fmt.Sprintf("pick one for %s", g.Name),
+			logo:    g.Logo,
+			choices: work.display,
+			images:  images,
+			inputs: map[string]textInput{
+				typeLink:     {title: fmt.Sprintf("type a link for %s:", g.Name), placeholder: "https://store.epicgames.com/..."},
+				refineSearch: {title: fmt.Sprintf("search the store for %s by:", g.Name), value: g.query()},
+			},
+		})
+		switch {
+		case errors.Is(err, errNoTerminal):
+			g.log.Info("no terminal to ask, left for review")
+			addPending(g)
+			return nil
+		case err != nil && len(sessionChoice()) > 0:
+			// decided for all while waiting, or interrupted by the pause
+			ans.choice = sessionChoice()
+		case err != nil:
+			return fmt.Errorf("you didn't select anything for %s: %w", g.Name, err)
+		case isSessionChoice(ans.choice):
+			setSession(ans.choice)
+		}
+	}
+	switch ans.choice {
+	case skipItem:
+		countMethod(methodSkip, 1)
+		addFailure(g.Name, "pick", nil)
+		dbPut(g.dbKey(), &result{Name: g.Name, Method: methodSkip})
+		if !uiSkipped(ctx, g) {
+			emitSkipped(g)
+		}
+		return nil
+	case acceptAll:
+		if m := work.best(); m != nil {
+			g.log.Info("accepted for all remaining", "match", m.Name, "confidence", m.Confidence)
+			g.writeMatch(ctx, methodAuto, m)
+			return nil
+		}
+		fallthrough // nothing to accept
+	case skipAll:
+		// not stored as skipped, so a later run asks again
+		countMethod(methodSkip, 1)
+		addFailure(g.Name, "pick", nil)
+		emitSkipped(g)
+		return nil
+	case pauseRun:
+		addPending(g)
+		return nil
+	case noLink:
+		g.write(ctx, methodNone, "", 0)
+		return nil
+	case typeLink:
+		if len(strings.TrimSpace(ans.text)) == 0 {
+			return fmt.Errorf("you didn't type anything for %s, skipping", g.Name)
+		}
+		return g.typed(ctx, ans.text)
+	case refineSearch:
+		return g.refine(ctx, strings.TrimSpace(ans.text))
+	case schByImg:
+		if err = g.searchByImg(ctx); err != nil {
+			g.log.Warn("logo search failed", "err", err)
+			addFailure(g.Name, "logo search", err)
+		}
+		return g.pick(ctx)
+	}
+	m := work.items[ans.index]
+	if len(g.Link) > 0 && m.Link == g.Link {
+		m.Confidence = 100
+		g.writeMatch(ctx, methodSource, &m)
+	} else if len(m.Name) > 0 {
+		g.writeMatch(ctx, methodPick, &m)
+	} else {
+		g.writeMatch(ctx, methodImage, &m)
+	}
+	return nil
+}
+
+// typed writes the typed link after validating it and confirming the title of its page, otherwise
+// asks again.
+func (g *game) typed(ctx context.Context, text string) error {
+	link, title, err := matcher.CheckLink(ctx, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		g.log.Warn("typed link is invalid", "link", text, "err", err)
+		return g.pickAgain(ctx)
+	}
+	if len(title) == 0 {
+		title = "no title"
+	}
+	ans, err := ask(ctx, &prompt{
+		name:    g.Name,
+		title:   fmt.Sprintf("%s is %q, write it for %s?", link, title, g.Name),
+		logo:    g.Logo,
+		choices: []string{useLink, backToList},
+	})
+	if err != nil {
+		return fmt.Errorf("you didn't confirm the link for %s: %w", g.Name, err)
+	}
+	if ans.choice != useLink {
+		return g.pickAgain(ctx)
+	}
+	g.write(ctx, methodTyped, link, 100)
+	return nil
+}
+
+// refine searches the store by the typed query, and asks again from its results. The results of
+// the previous search stay if it fails or finds nothing.
+func (g *game) refine(ctx context.Context, query string) error {
+	if len(query) == 0 {
+		return g.pickAgain(ctx)
+	}
+	matches, err := g.storeSearch(ctx, query)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil || len(matches) == 0 {
+		g.log.Warn("refined search found nothing", "query", query, "err", err)
+		return g.pickAgain(ctx)
+	}
+	g.refined = query
+	work := g.work
+	work.items, work.display = work.items[:0], work.display[:0]
+	work.add(matches...)
+	if len(g.Link) > 0 {
+		work.add(epicmatch.Match{Name: g.Name, Link: g.Link, Store: g.Source, Image: g.Logo})
+	}
+	return g.decide(ctx)
+}
+
+// query is the last search of the game for refining it, its name by default.
+func (g *game) query() string {
+	if len(g.refined) > 0 {
+		return g.refined
+	}
+	return g.Name
+}
+
+// pickAgain asks again from the same search results, without the choices added by pick.
+func (g *game) pickAgain(ctx context.Context) error {
+	g.work.display = g.work.display[:len(g.work.items)]
+	return g.decide(ctx)
+}
+
+// searchByImg searches by game logo and adds the results to the choices.
+func (g *game) searchByImg(ctx context.Context) error {
+	g.schdByImg = true
+	matches, err := matcher.SearchByImage(ctx, g.Logo)
+	work := g.work
+	// drop the extra choices of the previous pick
+	work.display = work.display[:len(work.items)]
+	work.add(matches...)
+	return err
+}
+
+// write sends the game with the found link to the output.
+func (g *game) write(ctx context.Context, method, link string, conf int) {
+	g.save(ctx, &result{Name: g.Name, Link: link, Confidence: conf, Logo: g.Logo, Method: method})
+}
+
+// writeMatch sends the game with the link of the search result to the output, or of the result of
+// the same base game by -prefer, with the slug of its base game for an edition or a bundle.
+func (g *game) writeMatch(ctx context.Context, method string, m *epicmatch.Match) {
+	var items []epicmatch.Match
+	if g.work != nil {
+		items = g.work.items
+	}
+	chosen := *m
+	if len(prefer) > 0 {
+		chosen = epicmatch.Prefer(items, chosen, prefer)
+		chosen.Confidence = m.Confidence
+	}
+	r := &result{Name: g.Name, Link: chosen.Link, Confidence: chosen.Confidence, Logo: g.Logo, Method: method,
+		Store: chosen.Store}
+	if base, ok := matcher.BaseGame(ctx, items, chosen); ok {
+		r.Base = epicmatch.Slug(base.Link)
+	}
+	if c := chosen.Category(); c != epicmatch.TypeGames {
+		r.Category = c
+	}
+	if explainMatches {
+		r.Explain = g.explain(r, m)
+	}
+	g.save(ctx, r)
+}
+
+// save emits the result, and stores it for later runs.
+func (g *game) save(ctx context.Context, r *result) {
+	r.index, r.Sources, r.wishlist, r.playStats = g.index, g.Sources, g.Wishlist, g.playStats
+	r.Notes = noteOf(g.Name)
+	if explainMatches && len(r.Explain) == 0 {
+		r.Explain = g.explain(r, nil)
+	}
+	g.stage("enrich")
+	enrich(ctx, r)
+	emit(r)
+	g.emitted = true
+	if !dryRun {
+		dbPut(g.dbKey(), r)
+	}
+}
+
+// enrich adds the details of the store to the result, if asked for.
+func enrich(ctx context.Context, r *result) {
+	followLink(ctx, r)
+	addPrice(ctx, r)
+	addMetadata(ctx, r)
+	addHero(ctx, r)
+	addGiveaways(r)
+	addAvailability(r)
+	r.Link = canonicalLink(r)
+}
+
+// addPrice fills in the current price of the result, if asked for or wanted.
+func addPrice(ctx context.Context, r *result) {
+	if !withPrices && !wanted(r) || dryRun || !epicmatch.IsProduct(r.Link) || r.Locked {
+		return
+	}
+	var err error
+	if r.Price, err = matcher.Price(ctx, r.Link); err != nil {
+		slog.Warn("failed to get price", "game", r.Name, "stage", "enrich", "err", err)
+		addFailure(r.Name, "price", err)
+	}
+}
+
+// addMetadata fills in the catalog details of the result, if asked for.
+func addMetadata(ctx context.Context, r *result) {
+	if !withMetadata || dryRun || !epicmatch.IsProduct(r.Link) || r.Locked {
+		return
+	}
+	var err error
+	if r.Metadata, err = matcher.Metadata(ctx, r.Link); err != nil {
+		slog.Warn("failed to get metadata", "game", r.Name, "stage", "enrich", "err", err)
+		addFailure(r.Name, "metadata", err)
+	}
+}
+
+// mustString is used for exiting on missing required input arguments.
+func mustString(in, descr string) {
+	if len(in) == 0 {
+		fmt.Printf("%s must be set\n", descr)
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+// mustPositive is used for exiting on invalid numeric input arguments.
+func mustPositive(in int, descr string) {
+	if in < 1 {
+		fmt.Printf("%s must be positive\n", descr)
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+func must(err error, descr string) {
+	if err != nil {
+		panic(fmt.Errorf("%s: %w", descr, err))
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// renderCmd is the subcommand writing the output again from a manifest, without any request.
+const renderCmd = "render"
+
+// manifestFlags are the flags changing the output of the same results, recorded in the manifest
+// and set from it by render.
+var manifestFlags = []string{"format", "template", "lang", "group-by", "order", "images", "link-locale", "mode"}
+
+var (
+	// manifestPath is the JSON file of how the output was made, by -manifest.
+	manifestPath string
+	manifestMtx  sync.Mutex
+	// manifestGames are the games emitted in the run in their order, manifestInputs are the
+	// digests of the inputs read.
+	manifestGames  []manifestGame
+	manifestInputs []manifestFile
+)
+
+// manifest tells how an output was made: from what inputs by which version, and how each game
+// was resolved. It's enough to write the same output again by render.
+type manifest struct {
+	Version  string            `json:"version"`
+	Started  time.Time         `json:"started"`
+	Finished time.Time         `json:"finished"`
+	Inputs   []manifestFile    `json:"inputs"`
+	Output   *manifestFile     `json:"output,omitempty"`
+	Flags    map[string]string `json:"flags"`
+	Games    []manifestGame    `json:"games"`
+}
+
+// manifestFile is a file read or written with its SHA-256 digest.
+type manifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// manifestGame is the result of a game with its position in the input, the skipped ones too.
+type manifestGame struct {
+	result
+	Index    int  `json:"index"`
+	Wishlist bool `json:"wishlist,omitempty"`
+}
+
+// addManifest records the emitted result for the manifest.
+func addManifest(r *result) {
+	if len(manifestPath) == 0 {
+		return
+	}
+	manifestMtx.Lock()
+	defer manifestMtx.Unlock()
+	manifestGames = append(manifestGames, manifestGame{result: *r, Index: r.index, Wishlist: r.wishlist})
+}
+
+// hashedInput records the digest of an input for the manifest when it's closed, the rest of it
+// read too.
+type hashedInput struct {
+	io.ReadCloser
+	path string
+	h    hash.Hash
+}
+
+// hashInput returns the input digested for the manifest by -manifest, as is without it.
+func hashInput(path string, rc io.ReadCloser) io.ReadCloser {
+	if len(manifestPath) == 0 {
+		return rc
+	}
+	return &hashedInput{ReadCloser: rc, path: path, h: sha256.New()}
+}
+
+func (hi *hashedInput) Read(p []byte) (int, error) {
+	n, err := hi.ReadCloser.Read(p)
+	hi.h.Write(p[:n])
+	return n, err
+}
+
+func (hi *hashedInput) Close() error {
+	_, err := io.Copy(hi.h, hi.ReadCloser)
+	manifestMtx.Lock()
+	manifestInputs = append(manifestInputs, manifestFile{Path: hi.path, SHA256: hex.EncodeToString(hi.h.Sum(nil))})
+	manifestMtx.Unlock()
+	if cerr := hi.ReadCloser.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writeManifest writes the manifest of the run with the digest of the output, if it's a file.
+// The games are in the input order, or in the order they were written for the resolved order.
+func writeManifest(outPath, order string) error {
+	manifestMtx.Lock()
+	defer manifestMtx.Unlock()
+	m := manifest{Version: currentVersion(), Started: started.UTC().Truncate(time.Second),
+		Finished: time.Now().UTC().Truncate(time.Second), Inputs: manifestInputs, Flags: map[string]string{},
+		Games: slices.Clone(manifestGames)}
+	if m.Inputs == nil {
+		m.Inputs = []manifestFile{}
+	}
+	if order != "resolved" {
+		slices.SortStableFunc(m.Games, func(a, b manifestGame) int { return a.Index - b.Index })
+	}
+	for _, name := range manifestFlags {
+		if f := flag.Lookup(name); f != nil {
+			m.Flags[name] = f.Value.String()
+		}
+	}
+	if localLinks {
+		// the links follow -lang, not the default -link-locale
+		m.Flags["link-locale"] = ""
+	}
+	if len(outPath) > 0 && outPath != stdio {
+		sum, err := fileDigest(outPath)
+		if err != nil {
+			return err
+		}
+		m.Output = &manifestFile{Path: outPath, SHA256: sum}
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, append(b, '\n'), 0644)
+}
+
+// fileDigest returns the SHA-256 digest of the file in hex.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readManifest reads the manifest for render, and sets the output flags from it, except for the
+// given ones of the command line. They're preferred to the config file, so the output is the same.
+func readManifest(path string, given map[string]bool) (*manifest, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var m manifest
+	if err = json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	for _, name := range manifestFlags {
+		value, ok := m.Flags[name]
+		if !ok || given[name] {
+			continue
+		}
+		if err = flag.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid %s of %s: %w", name, path, err)
+		}
+	}
+	return &m, nil
+}
+
+// games returns the games of the manifest for the progress, done already.
+func (m *manifest) games() []*game {
+	games := make([]*game, 0, len(m.Games))
+	for _, mg := range m.Games {
+		games = append(games, &game{Name: mg.Name, index: mg.Index, done: true})
+	}
+	return games
+}
+
+// render emits the results of the manifest in its order, as they were resolved, without
+// enriching them again. The skipped games are only recorded in the new manifest.
+func (m *manifest) render() {
+	for i := range m.Games {
+		mg := &m.Games[i]
+		r := &mg.result
+		r.index, r.wishlist = mg.Index, mg.Wishlist
+		if r.Method == methodSkip {
+			addManifest(r)
+		} else {
+			emit(r)
+		}
+		uiProgress()
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// poolShare is the part of -max-memory the free buffers of the store responses can take, 1/8.
+const poolShare = 8
+
+// sizeUnits are the binary units of the sizes, by their upper case suffix.
+var sizeUnits = map[string]int64{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+
+// parseSize returns the bytes of a size like 512M or 2GiB, in binary units.
+func parseSize(s string) (int64, error) {
+	u := strings.ToUpper(strings.TrimSpace(s))
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "B"), "I")
+	num := strings.TrimRight(u, "KMG")
+	mul, ok := sizeUnits[u[len(num):]]
+	n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+	if !ok || err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q, use bytes or K, M or G like 512M", s)
+	}
+	return n * mul, nil
+}
+
+// limitMemory makes the garbage collector keep the memory of the run under n bytes if it can, and
+// bounds the pooled response buffers to a part of it.
+func limitMemory(n int64) {
+	debug.SetMemoryLimit(n)
+	epicmatch.LimitPool(n / poolShare)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// maxRating is the highest rating of a game in the notes, drawn as stars.
+const maxRating = 5
+
+// notes are the annotations of the games by normalized name from -notes.
+var notes map[string]*annotation
+
+// annotation is what the user noted of a game, written into its card and the JSON output.
+type annotation struct {
+	Tags []string `yaml:"tags" json:"tags,omitempty"`
+	Note string   `yaml:"note" json:"note,omitempty"`
+	// Rating is from 1 to maxRating, 0 for none.
+	Rating int `yaml:"rating" json:"rating,omitempty"`
+}
+
+// UnmarshalYAML takes a bare string as the note, like "Celeste: replay on hard".
+func (a *annotation) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		return n.Decode(&a.Note)
+	}
+	type plain annotation
+	return n.Decode((*plain)(a))
+}
+
+// loadNotes reads the YAML map of game names to their tags, note and rating.
+func loadNotes(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var m map[string]*annotation
+	if err = yaml.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	notes = make(map[string]*annotation, len(m))
+	for name, a := range m {
+		if a == nil {
+			continue
+		}
+		if a.Rating < 0 || a.Rating > maxRating {
+			return fmt.Errorf("rating of %s must be 1 to %d in %s", name, maxRating, path)
+		}
+		a.Note = strings.TrimSpace(a.Note)
+		notes[normName(name)] = a
+	}
+	return nil
+}
+
+// noteOf returns the annotation of the game by the notes, nil if there's none.
+func noteOf(name string) *annotation {
+	return notes[normName(name)]
+}
+
+// tagsHTML formats the tags of the notes as badges of the card.
+func tagsHTML(a *annotation) string {
+	if a == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, t := range a.Tags {
+		fmt.Fprintf(&sb, `<span class="tag">%s</span>`, html.EscapeString(t))
+	}
+	return sb.String()
+}
+
+// noteHTML formats the rating and the note as a new line of the card, if any.
+func noteHTML(a *annotation) string {
+	if a == nil || a.Rating == 0 && len(a.Note) == 0 {
+		return ""
+	}
+	var parts []string
+	if a.Rating > 0 {
+		parts = append(parts, strings.Repeat("★", a.Rating)+strings.Repeat("☆", maxRating-a.Rating))
+	}
+	if len(a.Note) > 0 {
+		parts = append(parts, a.Note)
+	}
+	return fmt.Sprintf(`<br/><span class="note">%s</span>`, html.EscapeString(strings.Join(parts, " ")))
+}
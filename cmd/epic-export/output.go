@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+	"golang.org/x/net/html"
+)
+
+const (
+	// htmlHeader is formatted with the language, the title, and the titles of the theme and the
+	// view buttons by pageHeader.
+	htmlHeader = `<!DOCTYPE html><html lang="%s"><head><style>
+body{display:flex;flex-wrap:wrap;background:moccasin}div{margin:5px;padding:5px;border:blue 1px solid;text-align:center}
+img{width:300px;max-width:90vw;padding-top:5px}.price{color:darkgreen}.meta{color:dimgray;font-size:small}.note{color:saddlebrown;font-style:italic}.parts{font-size:small}
+.store,.source,.giveaway,.locked,.tag,.service{margin-left:5px;padding:0 4px;border-radius:3px;background:navy;color:white;font-size:small}
+.source{background:teal}.giveaway{background:darkgreen}.locked{background:darkred}.tag{background:purple}.geforce-now{background:olivedrab}.game-pass{background:green}details{width:100%%}section{display:flex;flex-wrap:wrap}
+summary{margin:5px;font-size:x-large;cursor:pointer}.empty{width:100%%;text-align:center;font-size:x-large}nav{order:-1;width:100%%}nav a{margin-right:8px}
+div:focus,summary:focus{outline:3px solid darkorange;outline-offset:2px}#controls{position:fixed;top:0;right:0;margin:5px;z-index:1}#controls button{font-size:large;cursor:pointer}
+html.dark body{background:#222;color:#ddd}html.dark a{color:#8ab4f8}html.dark div{border-color:#557}html.dark .meta{color:#aaa}html.dark .price{color:#7c7}html.dark .note{color:#db9}
+html.list body,html.list section{display:block}html.list div{display:flex;flex-wrap:wrap;align-items:center;text-align:left}html.list div br{display:none}html.list div>*{margin-right:6px}html.list img{width:120px;padding:0;order:-1}
+</style><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1"><title>%s</title>` + htmlScript + `</head><body>
+<p id="controls"><button id="theme" title="%s">&#9680;</button><button id="view" title="%s">&#9776;</button></p>
+`
+	// htmlScript restores the theme and the view of the page, toggled by its buttons, and moves
+	// between the cards and the sections by the arrow keys or the d-pad of a gamepad, like on a TV.
+	// It's a part of htmlHeader, so it has no percent signs.
+	htmlScript = `<script>
+(function(){
+var d=document.documentElement,s=null;try{s=window.localStorage}catch(e){}
+s=s||{getItem:function(){},setItem:function(){}};
+var theme=s.getItem("theme")||(matchMedia("(prefers-color-scheme: dark)").matches?"dark":"light");
+d.classList.toggle("dark",theme=="dark");d.classList.toggle("list",s.getItem("view")=="list");
+document.addEventListener("click",function(e){var b=e.target.closest&&e.target.closest("#controls button");if(!b)return;
+var c=b.id=="theme"?"dark":"list",on=d.classList.toggle(c);s.setItem(b.id,on?c:b.id=="theme"?"light":"grid")});
+function items(){return Array.prototype.filter.call(document.querySelectorAll("body div,summary"),function(n){return n.getClientRects().length})}
+function go(n){if(!n)return;if(!n.hasAttribute("tabindex"))n.tabIndex=-1;n.focus();n.scrollIntoView({block:"nearest"})}
+function key(k){var all=items(),a=document.activeElement,cur=a&&a.closest?a.closest("body div,summary"):null,i=all.indexOf(cur);
+if(k=="Enter"){var l=cur&&(cur.tagName=="DIV"?cur.querySelector("a"):cur);if(l)l.click();return!!l}
+if(k=="Home"||k=="End"){go(all[k=="Home"?0:all.length-1]);return true}
+if(i<0){go(all[0]);return true}
+if(k=="ArrowLeft"||k=="ArrowRight"){go(all[i+(k=="ArrowRight"?1:-1)]);return true}
+var r=cur.getBoundingClientRect(),down=k=="ArrowDown",best=null,bd=Infinity;
+all.forEach(function(n){var q=n.getBoundingClientRect(),dy=down?q.top-r.bottom:r.top-q.bottom;
+if(n==cur||dy<-r.height/2)return;var dist=Math.max(dy,0)*4+Math.abs(q.left-r.left);if(dist<bd){best=n;bd=dist}});
+go(best);return true}
+var keys={ArrowUp:1,ArrowDown:1,ArrowLeft:1,ArrowRight:1,Home:1,End:1,Enter:1};
+document.addEventListener("keydown",function(e){if(keys[e.key]&&!e.altKey&&!e.ctrlKey&&!e.metaKey&&key(e.key))e.preventDefault()});
+var pads=[[12,"ArrowUp"],[13,"ArrowDown"],[14,"ArrowLeft"],[15,"ArrowRight"],[0,"Enter"]],held={},polling=false;
+function poll(){var gs=navigator.getGamepads?navigator.getGamepads():[];
+for(var i=0;i<gs.length;i++){var g=gs[i];if(!g)continue;pads.forEach(function(p){var b=g.buttons[p[0]],on=!!(b&&b.pressed),id=i+":"+p[0];
+if(on&&!held[id])key(p[1]);held[id]=on})}requestAnimationFrame(poll)}
+addEventListener("gamepadconnected",function(){if(!polling){polling=true;poll()}});
+})();
+</script>`
+	htmlFooter = `</body></html>`
+	// emptyStart starts the note of the page without any cards, in place of the table of contents.
+	emptyStart = `<p class="empty">`
+	emptyFmt   = emptyStart + "%s</p>\n"
+	outFmt     = `<div id="%s"%s data-confidence="%d" title="%s: %d%%"><a href="%s">%s</a>%s%s%s</div>
+`
+	noLinkFmt = `<div id="%s"%s><span>%s</span>%s%s</div>
+`
+)
+
+// Match methods tell how the link of a result was found.
+const (
+	methodAlias      = "alias"      // link from the aliases file
+	methodMapping    = "mapping"    // link from an imported shared dataset
+	methodSlug       = "slug"       // naive slug guess of the product page
+	methodSearch     = "search"     // exact name match in store search
+	methodYear       = "year"       // one of the same named results by the release year of the input
+	methodPick       = "pick"       // user picked from search results
+	methodImage      = "image"      // user picked from logo search results
+	methodAuto       = "auto"       // best search result at or above the auto-accept threshold
+	methodHash       = "hash"       // search result with a thumbnail like the source logo
+	methodTyped      = "typed"      // user typed the link
+	methodSource     = "source"     // page of the game in its source library, without an Epic match
+	methodCollection = "collection" // components of a collection name, each by an exact match
+	methodNone       = "none"       // user chose to keep the game without a link
+	methodSkip       = "skip"       // user skipped the game, it's only stored, never written
+)
+
+// result is a single game ready to be written out.
+type result struct {
+	Name       string           `json:"name"`
+	Link       string           `json:"link,omitempty"`
+	Confidence int              `json:"confidence"`
+	Logo       string           `json:"logo"`
+	Method     string           `json:"method"`
+	Price      *epicmatch.Price `json:"price,omitempty"`
+	// Metadata are the catalog details of the product.
+	Metadata *epicmatch.Metadata `json:"metadata,omitempty"`
+	// Store is the name of the fallback store of the link, empty for Epic.
+	Store string `json:"store,omitempty"`
+	// Sources are the launchers of the game for many inputs.
+	Sources []string `json:"sources,omitempty"`
+	// Base is the product slug of the base game of an edition or a bundle.
+	Base string `json:"baseGame,omitempty"`
+	// Category is the product category of the store match, like editors, empty for games.
+	Category string `json:"category,omitempty"`
+	// Locked is true for a product page unavailable in the region of the store requests.
+	Locked bool `json:"regionLocked,omitempty"`
+	// Delisted is true for a written product page gone from the store by -check-delisted, Wayback
+	// is its archive then.
+	Delisted bool   `json:"delisted,omitempty"`
+	Wayback  string `json:"wayback,omitempty"`
+	// Giveaways are the periods the game was free on the store.
+	Giveaways []epicmatch.Giveaway `json:"giveaways,omitempty"`
+	// Available are the keys of the services the game is playable on by -availability, like
+	// geforce-now.
+	Available []string `json:"available,omitempty"`
+	// Explain tells why the game matched, by -explain.
+	Explain string `json:"explain,omitempty"`
+	// Hero is the landscape artwork of the store page by -images.
+	Hero string `json:"hero,omitempty"`
+	// Notes are the tags, the note and the rating of the game by -notes.
+	Notes *annotation `json:"notes,omitempty"`
+	// Parts are the components of a collection game, the link being of the first one found.
+	Parts []part `json:"parts,omitempty"`
+	// index is the position of the game in the input.
+	index int
+	// wishlist is true for a game flagged as wanted.
+	wishlist bool
+	playStats
+}
+
+// output writes results in a specific file format. Header and footer are written by begin and end.
+type output interface {
+	begin()
+	write(r *result)
+	end()
+}
+
+// newOutput returns the output for the given format name writing to w.
+func newOutput(format string, w *bufio.Writer) (output, error) {
+	switch format {
+	case "html":
+		return &htmlOutput{w: w}, nil
+	case "md":
+		return &mdOutput{w: w}, nil
+	case "csv":
+		return &csvOutput{w: csv.NewWriter(w)}, nil
+	case "json":
+		return &jsonOutput{w: w}, nil
+	case "obsidian":
+		return &obsidianOutput{w: w}, nil
+	case "opml":
+		return &opmlOutput{w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, use html, md, csv, json, obsidian or opml", format)
+}
+
+// htmlOutput writes a standalone gallery page of game cards, in collapsible sections by
+// -group-by, with a table of contents linking their anchors.
+type htmlOutput struct {
+	w *bufio.Writer
+	// section is the heading of the open section, if inSection.
+	section   string
+	inSection bool
+	anchors   anchors
+}
+
+// keep continues the table of contents of the merged page by its entries.
+func (o *htmlOutput) keep(toc string) {
+	o.anchors.kept = toc
+	o.anchors.reset()
+}
+
+func (o *htmlOutput) begin() {
+	o.w.WriteString(pageHeader())
+}
+
+func (o *htmlOutput) write(r *result) {
+	if grouper != nil {
+		if s := section(r); !o.inSection || s != o.section {
+			if o.inSection {
+				o.w.WriteString(sectionEnd)
+			}
+			fmt.Fprintf(o.w, sectionFmt, html.EscapeString(s))
+			o.section, o.inSection = s, true
+		}
+	}
+	name := html.EscapeString(r.Name)
+	id := o.anchors.add(r.Name)
+	var attrs string
+	if len(r.Explain) > 0 {
+		attrs = fmt.Sprintf(` data-explain="%s"`, html.EscapeString(r.Explain))
+	}
+	badge := giveawayHTML(r.Giveaways) + availabilityHTML(r.Available)
+	for _, s := range r.Sources {
+		badge += fmt.Sprintf(`<span class="source">%s</span>`, html.EscapeString(s))
+	}
+	badge += tagsHTML(r.Notes)
+	if len(r.Link) == 0 {
+		fmt.Fprintf(o.w, noLinkFmt, id, attrs, name, badge+playHTML(r.playStats)+noteHTML(r.Notes), imagesHTML(r))
+		return
+	}
+	if len(r.Store) > 0 {
+		badge = fmt.Sprintf(`<span class="store">%s</span>`, html.EscapeString(r.Store)) + badge
+	}
+	if r.Locked {
+		badge = fmt.Sprintf(`<span class="locked">%s</span>`, html.EscapeString(text.locked)) + badge
+	}
+	fmt.Fprintf(o.w, outFmt, id, attrs, r.Confidence, text.confidence, r.Confidence, html.EscapeString(localLink(r.Link)), name,
+		badge, partsHTML(r.Parts)+priceHTML(r.Price)+metadataHTML(r.Metadata)+playHTML(r.playStats)+noteHTML(r.Notes), imagesHTML(r))
+}
+
+// playHTML formats the playtime and the achievements as a new line of the card, if any.
+func playHTML(p playStats) string {
+	var parts []string
+	if p.Playtime > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f h", float64(p.Playtime)/60))
+	}
+	switch {
+	case p.AchievementsTotal > 0:
+		parts = append(parts, fmt.Sprintf("%d/%d %s", p.Achievements, p.AchievementsTotal, text.achievements))
+	case p.Achievements > 0:
+		parts = append(parts, fmt.Sprintf("%d %s", p.Achievements, text.achievements))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`<br/><span class="meta">%s</span>`, html.EscapeString(strings.Join(parts, " · ")))
+}
+
+// priceHTML formats the price as a new line of the card, if any.
+func priceHTML(p *epicmatch.Price) string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Free:
+		return `<br/><span class="price">` + html.EscapeString(text.free) + `</span>`
+	case p.Discount > 0:
+		return fmt.Sprintf(`<br/><span class="price">%s <s>%s</s> -%d%%</span>`,
+			html.EscapeString(p.Current), html.EscapeString(p.Original), p.Discount)
+	}
+	return fmt.Sprintf(`<br/><span class="price">%s</span>`, html.EscapeString(p.Current))
+}
+
+// metadataHTML formats the catalog details as a new line of the card, if any.
+func metadataHTML(md *epicmatch.Metadata) string {
+	if md == nil {
+		return ""
+	}
+	var parts []string
+	if by := md.Developer; len(by) > 0 {
+		if len(md.Publisher) > 0 && md.Publisher != by {
+			by += " / " + md.Publisher
+		}
+		parts = append(parts, by)
+	} else if len(md.Publisher) > 0 {
+		parts = append(parts, md.Publisher)
+	}
+	if len(md.Released) > 0 {
+		parts = append(parts, md.Released)
+	}
+	if len(md.Genres) > 0 {
+		parts = append(parts, strings.Join(md.Genres, ", "))
+	}
+	return fmt.Sprintf(`<br/><span class="meta">%s</span>`, html.EscapeString(strings.Join(parts, " · ")))
+}
+
+func (o *htmlOutput) end() {
+	if o.inSection {
+		// the section stays open for the results after a checkpoint
+		o.w.WriteString(sectionEnd)
+	}
+	if toc := o.anchors.html(); len(toc) > 0 {
+		o.w.WriteString(toc)
+	} else {
+		fmt.Fprintf(o.w, emptyFmt, html.EscapeString(text.empty))
+	}
+	o.w.WriteString(htmlFooter)
+}
+
+// mdOutput writes a Markdown table, usable by most static-site generators.
+type mdOutput struct {
+	w *bufio.Writer
+}
+
+func (o *mdOutput) begin() {
+	o.w.WriteString("| Name | Logo |\n| --- | --- |\n")
+}
+
+func (o *mdOutput) write(r *result) {
+	name := strings.ReplaceAll(r.Name, "|", `\|`)
+	if len(r.Link) > 0 {
+		name = fmt.Sprintf("[%s](%s)", name, r.Link)
+	}
+	if len(r.Parts) > 0 {
+		name += ": " + strings.ReplaceAll(partsMarkdown(r.Parts, ", "), "|", `\|`)
+	}
+	fmt.Fprintf(o.w, "| %s | ![logo](%s) |\n", name, r.Logo)
+}
+
+func (o *mdOutput) end() {}
+
+// csvOutput writes one record per game with a header line for spreadsheets.
+type csvOutput struct {
+	w *csv.Writer
+}
+
+func (o *csvOutput) begin() {
+	o.w.Write([]string{"name", "link", "confidence", "logo", "method"})
+}
+
+func (o *csvOutput) write(r *result) {
+	o.w.Write([]string{r.Name, r.Link, strconv.Itoa(r.Confidence), r.Logo, r.Method})
+	o.w.Flush() // into the output buffer, for the checkpoints
+}
+
+func (o *csvOutput) end() {
+	o.w.Flush()
+}
+
+// jsonOutput writes a JSON array of results.
+type jsonOutput struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (o *jsonOutput) begin() {
+	o.w.WriteString("[")
+}
+
+func (o *jsonOutput) write(r *result) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		slog.Error("failed to marshal result", "game", r.Name, "stage", "output", "err", err)
+		return
+	}
+	if o.count > 0 {
+		o.w.WriteByte(',')
+	}
+	o.count++
+	o.w.WriteString("\n  ")
+	o.w.Write(b)
+}
+
+func (o *jsonOutput) end() {
+	o.w.WriteString("\n]\n")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+var (
+	// ownedPath is the JSON file of the games owned on more than one store by -owned-report,
+	// empty without it.
+	ownedPath string
+	ownedMtx  sync.Mutex
+	owned     []*result
+)
+
+// ownedGame is a game owned on more than one store.
+type ownedGame struct {
+	Name string `json:"name"`
+	// Names are the other names of the same matched game in the inputs.
+	Names  []string `json:"names,omitempty"`
+	Stores []string `json:"stores"`
+	Link   string   `json:"link,omitempty"`
+	// OnEpic is true if one of the copies is on Epic, which may be uninstalled for another one.
+	OnEpic bool `json:"onEpic"`
+}
+
+// addOwned keeps the result for the ownership report.
+func addOwned(r *result) {
+	ownedMtx.Lock()
+	defer ownedMtx.Unlock()
+	owned = append(owned, r)
+}
+
+// ownedGames returns the games of more than one store. The games of the same name are merged by
+// readInputs already, and the games of another name matched to the same link are merged here.
+func ownedGames() []ownedGame {
+	games := []ownedGame{} // an empty list in the file
+	byLink := map[string]int{}
+	sortResults("input", owned)
+	for _, r := range owned {
+		i, ok := byLink[r.Link]
+		if !ok || len(r.Link) == 0 {
+			i = len(games)
+			games = append(games, ownedGame{Name: r.Name, Link: r.Link})
+			byLink[r.Link] = i
+		} else if !slices.Contains(games[i].Names, r.Name) && games[i].Name != r.Name {
+			games[i].Names = append(games[i].Names, r.Name)
+		}
+		for _, s := range r.Sources {
+			if !slices.Contains(games[i].Stores, s) {
+				games[i].Stores = append(games[i].Stores, s)
+			}
+		}
+	}
+	games = slices.DeleteFunc(games, func(g ownedGame) bool { return len(g.Stores) < 2 })
+	for i := range games {
+		games[i].OnEpic = slices.Contains(games[i].Stores, launchers["epic"])
+	}
+	slices.SortStableFunc(games, func(a, b ownedGame) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return games
+}
+
+// writeOwned prints the table of the games owned on more than one store to w, and writes them to
+// the JSON file.
+func writeOwned(w io.Writer) error {
+	ownedMtx.Lock()
+	defer ownedMtx.Unlock()
+	games := ownedGames()
+	fmt.Fprintf(w, "\n%d games owned on more than one store:\n", len(games))
+	if len(games) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "GAME\tSTORES\tON EPIC\tLINK")
+		for _, g := range games {
+			name := g.Name
+			if len(g.Names) > 0 {
+				name += " (" + strings.Join(g.Names, ", ") + ")"
+			}
+			onEpic := ""
+			if g.OnEpic {
+				onEpic = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, strings.Join(g.Stores, ", "), onEpic, g.Link)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(games, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ownedPath, append(b, '\n'), 0644)
+}
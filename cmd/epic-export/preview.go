@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+)
+
+const (
+	kittyChunk = 4096 // maximum payload of a kitty graphics escape
+	// cellWidth and cellHeight are the assumed pixel size of a terminal cell for sixel images.
+	cellWidth, cellHeight = 10, 20
+)
+
+// previews render an image into a box of terminal cells, returning the lines of the box. The slot
+// tells the images apart that replace each other, like the logo and the thumbnail.
+var previews = map[string]func(img image.Image, cols, rows, slot int) string{
+	"blocks": blockImage,
+	"kitty":  kittyImage,
+	"sixel":  sixelImage,
+}
+
+// preview is the renderer of the logos and thumbnails, see -preview.
+var preview = "auto"
+
+// detectPreview returns the best image renderer of the terminal by its environment, blocks work
+// in all true color terminals.
+func detectPreview() string {
+	term, prog := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case len(os.Getenv("KITTY_WINDOW_ID")) > 0, strings.Contains(term, "kitty"), strings.Contains(term, "ghostty"),
+		prog == "WezTerm", prog == "ghostty":
+		return "kitty"
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "mlterm"),
+		prog == "iTerm.app":
+		return "sixel"
+	}
+	return "blocks"
+}
+
+// imageBox returns the size of the image in cells fitting in width columns and maxRows rows, a
+// cell being twice as high as wide.
+func imageBox(b image.Rectangle, width, maxRows int) (cols, rows int) {
+	cols, rows = width, max(width*b.Dy()/b.Dx()/2, 1)
+	if rows > maxRows {
+		cols, rows = max(cols*maxRows/rows, 1), maxRows
+	}
+	return cols, rows
+}
+
+// blockImage renders the image with colored half blocks, two pixel rows per line.
+func blockImage(img image.Image, cols, rows, _ int) string {
+	b := img.Bounds()
+	var sb strings.Builder
+	for y := 0; y < rows*2; y += 2 {
+		for x := range cols {
+			px := b.Min.X + x*b.Dx()/cols
+			tr, tg, tb, _ := img.At(px, b.Min.Y+y*b.Dy()/(rows*2)).RGBA()
+			br, bg, bb, _ := img.At(px, b.Min.Y+(y+1)*b.Dy()/(rows*2)).RGBA()
+			fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr>>8, tg>>8, tb>>8, br>>8, bg>>8, bb>>8)
+		}
+		sb.WriteString("\x1b[0m\n")
+	}
+	return sb.String()
+}
+
+// kittyImage sends the image by the kitty graphics protocol as PNG, scaled to the box by the
+// terminal. It's placed under the text of the box, replacing the previous image of the slot.
+func kittyImage(img image.Image, cols, rows, slot int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return blockImage(img, cols, rows, slot)
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	var sb strings.Builder
+	// slots are negative z-indexes, so the spaces of the box don't hide the image
+	fmt.Fprintf(&sb, "\x1b7\x1b_Ga=d,d=z,z=%d,q=2\x1b\\", -slot-1)
+	for i := 0; i < len(data); i += kittyChunk {
+		chunk, more := data[i:min(i+kittyChunk, len(data))], 0
+		if i+kittyChunk < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Gf=100,a=T,q=2,C=1,z=%d,c=%d,r=%d,m=%d;%s\x1b\\", -slot-1, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	sb.WriteString("\x1b8")
+	return sb.String() + boxLines(cols, rows, "")
+}
+
+// boxLines returns the lines of spaces of the box keeping the place of an image in the layout,
+// the last one ending with the escape.
+func boxLines(cols, rows int, last string) string {
+	line := strings.Repeat(" ", cols)
+	return strings.Repeat(line+"\n", rows-1) + line + last + "\n"
+}
+
+// sixelImage encodes the image as sixels in a 256 color palette, scaled to the box by the
+// assumed cell size. Sixels replace the text under them, so they're drawn from the end of the box
+// after its spaces.
+func sixelImage(img image.Image, cols, rows, _ int) string {
+	img = flatten(img)
+	w, h := cols*cellWidth, rows*cellHeight
+	pal := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+	b := img.Bounds()
+	for y := range h {
+		for x := range w {
+			pal.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\x1bP0;1q\"1;1;%d;%d", w, h)
+	for i, c := range pal.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+	for band := 0; band < h; band += 6 {
+		used := map[uint8]bool{}
+		for y := band; y < min(band+6, h); y++ {
+			for x := range w {
+				used[pal.ColorIndexAt(x, y)] = true
+			}
+		}
+		for ci := range pal.Palette {
+			if !used[uint8(ci)] {
+				continue
+			}
+			fmt.Fprintf(&sb, "#%d", ci)
+			writeSixelRow(&sb, pal, uint8(ci), band, w, h)
+			sb.WriteByte('$') // back to the start of the band for the next color
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\x1b\\")
+	up := ""
+	if rows > 1 {
+		up = fmt.Sprintf("\x1b[%dA", rows-1)
+	}
+	return boxLines(cols, rows, fmt.Sprintf("\x1b7%s\x1b[%dD%s\x1b8", up, cols, sb.String()))
+}
+
+// writeSixelRow writes the sixels of the color in the band of six rows, run length encoded.
+func writeSixelRow(sb *strings.Builder, pal *image.Paletted, ci uint8, band, w, h int) {
+	var last byte
+	run := 0
+	flush := func() {
+		switch {
+		case run > 3:
+			fmt.Fprintf(sb, "!%d%c", run, last)
+		case run > 0:
+			sb.WriteString(strings.Repeat(string(last), run))
+		}
+	}
+	for x := range w {
+		var bits byte
+		for dy := range 6 {
+			if y := band + dy; y < h && pal.ColorIndexAt(x, y) == ci {
+				bits |= 1 << dy
+			}
+		}
+		if ch := 63 + bits; ch == last {
+			run++
+		} else {
+			flush()
+			last, run = ch, 1
+		}
+	}
+	flush()
+}
+
+// flatten draws the image over a white background, for transparent logos.
+func flatten(img image.Image) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+	return dst
+}
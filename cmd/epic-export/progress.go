@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// progressEvery is the interval of the progress lines without a terminal UI.
+const progressEvery = 10 * time.Second
+
+var (
+	// started is the start of resolving the games, for the rate.
+	started time.Time
+	// resolved is the number of processed games.
+	resolved atomic.Int64
+)
+
+// progressLine returns the number of resolved games with the rate and the estimated time left, and
+// the countdown of a pause.
+func progressLine(done, total int) string {
+	line := fmt.Sprintf("%d/%d resolved", done, total)
+	elapsed := time.Since(started)
+	if done == 0 || elapsed <= 0 {
+		return line + pauseLine()
+	}
+	rate := float64(done) / elapsed.Seconds()
+	left := time.Duration(float64(total-done) / rate * float64(time.Second))
+	return fmt.Sprintf("%s, %.2f games/s, ETA %s%s", line, rate, left.Round(time.Second), pauseLine())
+}
+
+// pauseLine returns the countdown of the pause of the store requests after too many challenges,
+// empty when they aren't paused.
+func pauseLine() string {
+	if matcher == nil {
+		return ""
+	}
+	left := time.Until(matcher.PausedUntil())
+	if left <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(", paused by challenges, resuming in %s", left.Round(time.Second))
+}
+
+// printProgress prints the progress line to stderr periodically until done is closed.
+func printProgress(total int, done <-chan struct{}) {
+	t := time.NewTicker(progressEvery)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			fmt.Fprintln(os.Stderr, progressLine(int(resolved.Load()), total))
+		case <-done:
+			fmt.Fprintln(os.Stderr, progressLine(int(resolved.Load()), total))
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+var (
+	// dryRun does all lookups without asking the user or writing the output, only the report.
+	dryRun bool
+	rep    report
+)
+
+// report is the summary of the match quality of a dry run.
+type report struct {
+	mtx       sync.Mutex
+	Exact     []reportItem `json:"exact"`
+	Stored    []reportItem `json:"stored"`
+	Fuzzy     []reportItem `json:"fuzzy"`
+	Unmatched []string     `json:"unmatched"`
+}
+
+type reportItem struct {
+	Name string `json:"name"`
+	Link string `json:"link,omitempty"`
+	// Match is the name of the best search result for fuzzy matches.
+	Match      string `json:"match,omitempty"`
+	Distance   int    `json:"distance,omitempty"`
+	Confidence int    `json:"confidence"`
+	Method     string `json:"method"`
+	Store      string `json:"store,omitempty"`
+}
+
+// emit writes the result to the output, or only to the wishlist if it's wanted, or adds it to the
+// report on a dry run.
+func emit(r *result) {
+	countMethod(r.Method, 1)
+	addContrib(r)
+	if len(ownedPath) > 0 && !wanted(r) {
+		addOwned(r)
+	}
+	if !dryRun {
+		addManifest(r)
+		addWish(r)
+		if wanted(r) {
+			return
+		}
+		if len(exportTo) > 0 {
+			addExport(r)
+		}
+		if len(feedPath) > 0 {
+			addFeed(r)
+		}
+		addHook(r)
+		batches.add(r)
+		results <- r
+		return
+	}
+	item := reportItem{Name: r.Name, Link: r.Link, Confidence: r.Confidence, Method: r.Method, Store: r.Store}
+	rep.mtx.Lock()
+	defer rep.mtx.Unlock()
+	switch r.Method {
+	case methodAlias, methodMapping, methodSlug, methodSearch, methodYear, methodCollection:
+		rep.Exact = append(rep.Exact, item)
+	default:
+		rep.Stored = append(rep.Stored, item)
+	}
+}
+
+// emitSkipped passes the game skipped by the user to the unresolved hook and the wishlist.
+func emitSkipped(g *game) {
+	r := &result{Name: g.Name, Logo: g.Logo, Method: methodSkip, Sources: g.Sources, index: g.index, wishlist: g.Wishlist,
+		playStats: g.playStats}
+	addHook(r)
+	addWish(r)
+	addManifest(r)
+}
+
+// addFuzzy adds the best search result of the game to the report instead of asking the user.
+func (r *report) addFuzzy(g *game) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	work := g.work
+	if len(work.items) == 0 {
+		r.Unmatched = append(r.Unmatched, g.Name)
+		return
+	}
+	m := work.items[0]
+	item := reportItem{Name: g.Name, Link: m.Link, Match: m.Name, Distance: m.Rank, Confidence: m.Confidence,
+		Method: methodPick, Store: m.Store}
+	if len(m.Name) == 0 {
+		item.Method = methodImage
+	}
+	r.Fuzzy = append(r.Fuzzy, item)
+}
+
+// write prints the report as JSON or as aligned text.
+func (r *report) write(w io.Writer, asJSON bool) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, items := range [][]reportItem{r.Exact, r.Stored, r.Fuzzy} {
+		sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	}
+	sort.Strings(r.Unmatched)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "exact matches: %d\n", len(r.Exact))
+	for _, it := range r.Exact {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", it.Name, it.Method, it.Link)
+	}
+	fmt.Fprintf(tw, "stored matches: %d\n", len(r.Stored))
+	for _, it := range r.Stored {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", it.Name, it.Method, it.Link)
+	}
+	fmt.Fprintf(tw, "fuzzy matches: %d\n", len(r.Fuzzy))
+	for _, it := range r.Fuzzy {
+		fmt.Fprintf(tw, "  %s\t~ %s\tdistance %d\tconfidence %d\t%s\n", it.Name, it.Match, it.Distance, it.Confidence, it.Link)
+	}
+	fmt.Fprintf(tw, "unmatched: %d\n", len(r.Unmatched))
+	for _, name := range r.Unmatched {
+		fmt.Fprintf(tw, "  %s\n", name)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+var (
+	// retryDelay is the delay between store requests of the retry pass, 0 or less disables it.
+	retryDelay = 5 * time.Second
+	retryMtx   sync.Mutex
+	// retryQueue are the games failed by transient errors in the main pass.
+	retryQueue []*game
+)
+
+// retry queues the game for the retry pass if the error is transient, and returns true if it
+// did. Games fail as usual on the second time.
+func (g *game) retry(err error) bool {
+	if retryDelay <= 0 || g.retried || !epicmatch.IsTransient(err) {
+		return false
+	}
+	g.log.Warn("transient failure, retrying after the main pass", "err", err)
+	g.retrying = true
+	retryMtx.Lock()
+	defer retryMtx.Unlock()
+	retryQueue = append(retryQueue, g)
+	return true
+}
+
+// retryPass resolves the queued games again with the slower retry delay, after the main pass.
+func retryPass(ctx context.Context, tokens chan *work) {
+	retryMtx.Lock()
+	queue := retryQueue
+	retryQueue = nil
+	retryMtx.Unlock()
+	if len(queue) == 0 || ctx.Err() != nil {
+		return
+	}
+	slog.Info("retrying games failed by transient errors", "games", len(queue), "delay", retryDelay)
+	matcher.SetDelay(retryDelay)
+	for _, g := range queue {
+		g.retrying, g.retried, g.isFuzzy = false, true, false
+	}
+	// they were counted in the progress already
+	runPass(ctx, queue, tokens, func() {})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// reviewCmd is the subcommand asking only the pending games of a headless run, and merging the
+// decisions into the existing output.
+const reviewCmd = "review"
+
+var (
+	// pendingPath is the file of the games left for review by a headless run.
+	pendingPath string
+	pendMtx     sync.Mutex
+	pending     []*game
+)
+
+// addPending leaves the game for the review, as there's no terminal to ask.
+func addPending(g *game) {
+	pendMtx.Lock()
+	defer pendMtx.Unlock()
+	pending = append(pending, g)
+}
+
+// writePending writes the pending games in the Epic input format, so the review can read them.
+// A stale file is removed if there are none.
+func writePending() error {
+	pendMtx.Lock()
+	defer pendMtx.Unlock()
+	if len(pending) == 0 {
+		if err := os.Remove(pendingPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(appData{Data: data{Applications: pending}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(pendingPath, b, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d games need your decision, run: epic-export %s -o <output> %s\n",
+		len(pending), reviewCmd, pendingPath)
+	return nil
+}
+
+// tails are what the outputs write at their end, the merged results are inserted before.
+var tails = map[string]string{
+	"html":     htmlFooter,
+	"md":       "",
+	"csv":      "",
+	"json":     "\n]\n",
+	"obsidian": "",
+	"opml":     opmlFooter,
+}
+
+// copyMerged copies the existing output file without its tail to f, so the output continues it
+// without begin. items tells if a JSON output has results already, and toc are the entries of the
+// table of contents of an HTML page, written again after the new cards.
+func copyMerged(f *os.File, path, format string) (items bool, toc string, err error) {
+	tail, ok := tails[format]
+	if !ok {
+		return false, "", fmt.Errorf("unknown output format %q", format)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false, "", err
+	}
+	end := bytes.LastIndex(b, []byte(tail))
+	if end < 0 {
+		return false, "", fmt.Errorf("%s doesn't end like a %s output", path, format)
+	}
+	b = markDelisted(b[:end], format)
+	if format == "html" {
+		b, toc = cutTOC(cutEmpty(b))
+	}
+	if _, err = f.Write(b); err != nil {
+		return false, "", err
+	}
+	return bytes.Contains(b, []byte("{")), toc, nil
+}
+
+// cutEmpty returns the page without its note of no cards, if any.
+func cutEmpty(page []byte) []byte {
+	if i := bytes.LastIndex(page, []byte(emptyStart)); i >= 0 {
+		return page[:i]
+	}
+	return page
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+const (
+	// versionCmd is the subcommand printing the version of the binary and the latest release.
+	versionCmd = "version"
+	// selfUpdateCmd is the subcommand replacing the binary with the latest release.
+	selfUpdateCmd = "self-update"
+
+	// releasesLink is the latest release of the repository on GitHub.
+	releasesLink = "https://api.github.com/repos/vendelin8/epic-export/releases/latest"
+	// checksumsAsset is the release asset of the SHA-256 sums of the binaries, in sha256sum format.
+	checksumsAsset = "checksums.txt"
+)
+
+// version is the release of the binary, set by -ldflags "-X main.version=v1.2.3", or taken from
+// the build info of go install.
+var version string
+
+// release is the latest release of the repository.
+type release struct {
+	Tag    string `json:"tag_name"`
+	Link   string `json:"html_url"`
+	Assets []struct {
+		Name string `json:"name"`
+		Link string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// asset returns the download link of the asset of the name, empty if there's none.
+func (r *release) asset(name string) string {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.Link
+		}
+	}
+	return ""
+}
+
+// binaryAsset is the name of the release binary of the platform, like epic-export_linux_amd64.
+func binaryAsset() string {
+	name := fmt.Sprintf("epic-export_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// currentVersion returns the version of the binary, (devel) for a build from source.
+func currentVersion() string {
+	if len(version) > 0 {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && len(info.Main.Version) > 0 {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// newer tells if the version a is a later release than b, like v1.10.0 than v1.9.2, or v1.2.0
+// than v1.2.0-rc.1. A version that isn't a release, like (devel), is never newer, and any release
+// is newer than it.
+func newer(a, b string) bool {
+	va, preA, okA := parseVersion(a)
+	vb, preB, okB := parseVersion(b)
+	if !okA || !okB {
+		return okA
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] > vb[i]
+		}
+	}
+	return !preA && preB
+}
+
+// parseVersion returns the major, minor and patch numbers of a version like v1.2.3, and if it's a
+// pre-release or a pseudo-version before them.
+func parseVersion(v string) ([3]int, bool, bool) {
+	var nums [3]int
+	v, ok := strings.CutPrefix(v, "v")
+	if !ok {
+		return nums, false, false
+	}
+	v, _, pre := strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return nums, false, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nums, false, false
+		}
+		nums[i] = n
+	}
+	return nums, pre, true
+}
+
+// latestRelease returns the latest release from GitHub.
+func latestRelease(ctx context.Context) (*release, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", releasesLink, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("accept", "application/vnd.github+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to get the latest release: %s", resp.Status)
+	}
+	var r release
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse the latest release: %w", err)
+	}
+	return &r, nil
+}
+
+// printVersion prints the version of the binary, and the latest release if it's newer.
+func printVersion(ctx context.Context) {
+	current := currentVersion()
+	fmt.Printf("epic-export %s %s/%s\n", current, runtime.GOOS, runtime.GOARCH)
+	r, err := latestRelease(ctx)
+	if err != nil {
+		slog.Warn("failed to check for updates", "err", err)
+		return
+	}
+	if newer(r.Tag, current) {
+		fmt.Printf("%s is available at %s, update by: epic-export %s\n", r.Tag, r.Link, selfUpdateCmd)
+	}
+}
+
+// selfUpdate replaces the running binary with the binary of the latest release of the platform,
+// after checking it against the checksums of the release.
+func selfUpdate(ctx context.Context) error {
+	current := currentVersion()
+	r, err := latestRelease(ctx)
+	if err != nil {
+		return err
+	}
+	if !newer(r.Tag, current) {
+		fmt.Printf("epic-export %s is up to date\n", current)
+		return nil
+	}
+	name := binaryAsset()
+	link, sumsLink := r.asset(name), r.asset(checksumsAsset)
+	if len(link) == 0 || len(sumsLink) == 0 {
+		return fmt.Errorf("release %s has no %s with %s", r.Tag, name, checksumsAsset)
+	}
+	want, err := releaseChecksum(ctx, sumsLink, name)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	tmp, err := download(ctx, link, filepath.Dir(exe), want)
+	if err != nil {
+		return err
+	}
+	if err = replaceBinary(exe, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	fmt.Printf("updated epic-export from %s to %s\n", current, r.Tag)
+	return nil
+}
+
+// releaseChecksum returns the SHA-256 sum of the asset of the name from the checksums of the
+// release.
+func releaseChecksum(ctx context.Context, link, name string) (string, error) {
+	body, err := fetch(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	sc := bufio.NewScanner(body)
+	for sc.Scan() {
+		// sha256sum lines are the sum and the name, with a * before the name of binary mode
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err = sc.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum of %s in %s", name, checksumsAsset)
+}
+
+// download writes the binary of the link into a new file of the directory, and returns its path
+// if its SHA-256 sum is the wanted one.
+func download(ctx context.Context, link, dir, want string) (string, error) {
+	body, err := fetch(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	f, err := os.CreateTemp(dir, ".epic-export-*")
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			err = fmt.Errorf("checksum mismatch of the downloaded binary: got %s, want %s", got, want)
+		}
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0755)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// replaceBinary moves the new binary in place of the running one. The running one is moved aside
+// first, as Windows can rename it but not overwrite it, and moved back if the replace fails.
+func replaceBinary(exe, tmp string) error {
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		return errors.Join(err, os.Rename(old, exe))
+	}
+	if err := os.Remove(old); err != nil {
+		slog.Debug("failed to remove the previous binary", "path", old, "err", err)
+	}
+	return nil
+}
+
+// fetch returns the body of the link, or an error for an error status.
+func fetch(ctx context.Context, link string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %s", link, resp.Status)
+	}
+	return resp.Body, nil
+}
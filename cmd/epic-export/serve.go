@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// webUI keeps the prompts waiting for a choice in the browser, nil without -serve.
+var webUI *webPrompts
+
+// webPrompts is the queue of prompts answered on the web page.
+type webPrompts struct {
+	mu     sync.Mutex
+	nextID int
+	queue  []*webPrompt
+}
+
+type webPrompt struct {
+	ID int
+	*prompt
+}
+
+// webPage shows the first prompt with the source logo next to the store thumbnails of the choices.
+var webPage = template.Must(template.New("page").Parse(`<!DOCTYPE html><html lang="en"><head><meta charset="utf-8">
+<title>epic-export{{with .Prompt}}: {{.Name}}{{end}}</title>{{if not .Prompt}}<meta http-equiv="refresh" content="3">{{end}}
+<style>body{font-family:sans-serif;background:moccasin;display:flex;gap:20px}
+.source{position:sticky;top:10px;align-self:flex-start;width:320px}.source img{width:300px}
+button{display:flex;align-items:center;gap:10px;width:100%;margin:4px 0;padding:4px;text-align:left;cursor:pointer}
+button img{width:160px;min-height:40px}</style></head><body>
+{{with .Prompt}}<div class="source"><p>{{$.Waiting}} waiting for you</p><h2>{{.Name}}</h2>
+{{if .Logo}}<img src="{{.Logo}}" alt="logo of {{.Name}}">{{end}}</div>
+<div><h3>{{.Title}}</h3>{{range $i, $c := .Choices}}
+<form method="post" action="/pick"><input type="hidden" name="id" value="{{$.Prompt.ID}}"><input type="hidden" name="index" value="{{$i}}">
+{{with $c.Input}}<input name="text" size="60" placeholder="{{.Placeholder}}" value="{{.Value}}" required> {{end}}
+<button type="submit">{{with $c.Image}}<img src="{{.}}" alt="">{{end}}<span>{{$c.Text}}</span></button></form>{{end}}</div>
+{{else}}<p>{{if .Finished}}all games processed, you can close this page{{else}}searching, the choices show up here...{{end}}</p>{{end}}
+</body></html>`))
+
+// webView is the data of the page for a prompt.
+type webView struct {
+	ID                int
+	Name, Title, Logo string
+	Choices           []webChoice
+}
+
+type webChoice struct {
+	Text, Image string
+	// Input is the text the choice asks to type, if any.
+	Input *webInput
+}
+
+type webInput struct {
+	Placeholder, Value string
+}
+
+// serve starts the web UI on the address, and stops it when the context is done.
+func serve(ctx context.Context, addr string, done <-chan struct{}) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	webUI = &webPrompts{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		webUI.page(w, done)
+	})
+	mux.HandleFunc("POST /pick", webUI.pick)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("web UI failed", "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	slog.Info("web UI is running, open it to pick the matches", "url", "http://"+ln.Addr().String())
+	return nil
+}
+
+// ask queues the prompt for the page and waits for the answer.
+func (s *webPrompts) ask(ctx context.Context, p *prompt) (answer, error) {
+	p.reply = make(chan answer, 1)
+	s.mu.Lock()
+	s.nextID++
+	wp := &webPrompt{ID: s.nextID, prompt: p}
+	s.queue = append(s.queue, wp)
+	s.mu.Unlock()
+	select {
+	case a := <-p.reply:
+		return a, nil
+	case <-sessionSet:
+		return answer{}, errSession
+	case <-ctx.Done():
+		s.mu.Lock()
+		s.queue = slices.DeleteFunc(s.queue, func(q *webPrompt) bool { return q == wp })
+		s.mu.Unlock()
+		return answer{}, ctx.Err()
+	}
+}
+
+// clear drops the waiting prompts, as they're decided for all.
+func (s *webPrompts) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = nil
+}
+
+func (s *webPrompts) page(w http.ResponseWriter, done <-chan struct{}) {
+	data := struct {
+		Prompt   *webView
+		Waiting  int
+		Finished bool
+	}{}
+	select {
+	case <-done:
+		data.Finished = true
+	default:
+	}
+	s.mu.Lock()
+	if data.Waiting = len(s.queue); data.Waiting > 0 {
+		p := s.queue[0]
+		choices := make([]webChoice, len(p.choices))
+		for i, c := range p.choices {
+			choices[i].Text = c
+			if i < len(p.images) {
+				choices[i].Image = p.images[i]
+			}
+			if in, ok := p.inputs[c]; ok {
+				choices[i].Input = &webInput{Placeholder: in.placeholder, Value: in.value}
+			}
+		}
+		data.Prompt = &webView{ID: p.ID, Name: p.name, Title: p.title, Logo: p.logo, Choices: choices}
+	}
+	s.mu.Unlock()
+	if err := webPage.Execute(w, data); err != nil {
+		slog.Error("failed to render web UI", "err", err)
+	}
+}
+
+// pick answers the prompt of the posted form, then shows the next one.
+func (s *webPrompts) pick(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	index, err2 := strconv.Atoi(r.FormValue("index"))
+	if err != nil || err2 != nil {
+		http.Error(w, "bad choice", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	i := slices.IndexFunc(s.queue, func(q *webPrompt) bool { return q.ID == id })
+	var p *webPrompt
+	if i >= 0 && index >= 0 && index < len(s.queue[i].choices) {
+		p = s.queue[i]
+		s.queue = slices.Delete(s.queue, i, i+1)
+	}
+	s.mu.Unlock()
+	if p != nil {
+		p.reply <- answer{choice: p.choices[index], index: index, text: r.FormValue("text")}
+	}
+	// an unknown prompt was answered already, eg. in another tab
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
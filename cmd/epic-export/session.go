@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Choices of the picker deciding for all the remaining games of the run.
+const (
+	acceptAll = "Accept best for all remaining"
+	skipAll   = "Skip all remaining"
+	pauseRun  = "Pause and save session"
+)
+
+var (
+	// session is the choice for all the remaining games once picked, see setSession.
+	session atomic.Value
+	// sessionSet is closed when the session choice is picked, so the waiting prompts stop.
+	sessionSet  = make(chan struct{})
+	sessionOnce sync.Once
+	// stopRun cancels the run for pausing it.
+	stopRun context.CancelFunc
+	// deadlineHit is set when the -deadline of the run passed, pausing it.
+	deadlineHit atomic.Bool
+
+	errSession = errors.New("decided for all remaining games")
+)
+
+// sessionChoice returns the choice for all the remaining games, empty while each one is asked.
+func sessionChoice() string {
+	s, _ := session.Load().(string)
+	return s
+}
+
+// isSessionChoice tells if the choice decides for all the remaining games.
+func isSessionChoice(choice string) bool {
+	return choice == acceptAll || choice == skipAll || choice == pauseRun
+}
+
+// setSession decides for all the remaining games by the choice, only the first one counts. The
+// prompts waiting are dropped, and pausing stops the run, leaving the rest for the review.
+func setSession(choice string) {
+	sessionOnce.Do(func() {
+		session.Store(choice)
+		close(sessionSet)
+		if uiRunning.Load() {
+			ui.Send(sessionMsg{})
+		}
+		if webUI != nil {
+			webUI.clear()
+		}
+		if choice == pauseRun {
+			stopRun()
+		}
+	})
+}
+
+// paused tells if the user or the deadline paused the run.
+func paused() bool {
+	return sessionChoice() == pauseRun || deadlineHit.Load()
+}
+
+// pauseAt pauses the run after the deadline like the user would, unless it's finished by then.
+func pauseAt(deadline time.Duration, done <-chan struct{}) {
+	t := time.NewTimer(deadline)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		slog.Warn("deadline passed, leaving the unfinished games for review", "deadline", deadline)
+		deadlineHit.Store(true)
+		setSession(pauseRun)
+		stopRun() // decided for all before
+	case <-done:
+	}
+}
+
+// pendRest leaves the unfinished games of a paused run for the review, except the ones pending
+// already.
+func pendRest(games []*game) {
+	pendMtx.Lock()
+	defer pendMtx.Unlock()
+	left := make(map[*game]bool, len(pending))
+	for _, g := range pending {
+		left[g] = true
+	}
+	for _, g := range games {
+		if !g.done && !left[g] {
+			pending = append(pending, g)
+		}
+	}
+}
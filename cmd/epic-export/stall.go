@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	// stallAfter is the time without progress of a game after which it's reported as stalled, by
+	// -stall-after, 0 disables the watchdog.
+	stallAfter = 10 * time.Minute
+	// stallKill cancels the stalled games, and retries them after the main pass, by -stall-kill.
+	stallKill bool
+	// errStalled is the cause of canceling a stalled game.
+	errStalled = errors.New("no progress, stalled")
+
+	stallMtx sync.Mutex
+	// watched are the games being resolved or asked.
+	watched = map[*game]*watchedGame{}
+)
+
+// watchedGame is a game checked by the watchdog.
+type watchedGame struct {
+	// cancel stops the resolving of the game, nil for a game the user is asked about.
+	cancel context.CancelCauseFunc
+	// reported is the last progress of the game reported as stalled.
+	reported time.Time
+}
+
+// watchGame makes the watchdog check the progress of the game until unwatch is called. cancel
+// stops its resolving for -stall-kill, nil for a game the user is asked about.
+func watchGame(g *game, cancel context.CancelCauseFunc) (unwatch func()) {
+	stallMtx.Lock()
+	g.lastBeat = time.Now()
+	watched[g] = &watchedGame{cancel: cancel}
+	stallMtx.Unlock()
+	return func() {
+		stallMtx.Lock()
+		defer stallMtx.Unlock()
+		delete(watched, g)
+	}
+}
+
+// beat records the progress of the game by a response of the store, without a new stage.
+func (g *game) beat() {
+	stallMtx.Lock()
+	defer stallMtx.Unlock()
+	g.lastBeat = time.Now()
+}
+
+// watchStalls reports the games without progress for stallAfter, once per stage, until the
+// context is done. A prompt waiting that long is reported too, as it may be blocked, but it's
+// the user's to answer, so only the resolving games are canceled by -stall-kill.
+func watchStalls(ctx context.Context) {
+	t := time.NewTicker(max(stallAfter/4, time.Second))
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		stallMtx.Lock()
+		for g, w := range watched {
+			idle := time.Since(g.lastBeat)
+			if idle < stallAfter || w.reported.Equal(g.lastBeat) {
+				continue
+			}
+			w.reported = g.lastBeat
+			switch {
+			case w.cancel == nil:
+				g.log.Warn("no progress, the prompt may be blocked", "for", idle.Round(time.Second))
+			case stallKill:
+				g.log.Warn("no progress, canceling the stalled game", "for", idle.Round(time.Second))
+				w.cancel(errStalled)
+			default:
+				g.log.Warn("no progress, the game is stalled", "for", idle.Round(time.Second))
+			}
+		}
+		stallMtx.Unlock()
+	}
+}
+
+// requeueStalled queues the canceled stalled game for the retry pass, or records its failure if
+// it's stalled again there.
+func (g *game) requeueStalled() {
+	if g.retried || retryDelay <= 0 {
+		addFailure(g.Name, "stall", errStalled)
+		return
+	}
+	slog.Info("retrying the stalled game after the main pass", "game", g.Name, "stage", "stall")
+	g.retrying = true
+	retryMtx.Lock()
+	defer retryMtx.Unlock()
+	retryQueue = append(retryQueue, g)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// statMethods are the match methods in the order of the stats.
+var statMethods = []string{methodAlias, methodMapping, methodSlug, methodSearch, methodYear, methodAuto, methodHash, methodPick,
+	methodImage, methodTyped, methodSource, methodCollection, methodNone, methodSkip}
+
+var (
+	statsMtx sync.Mutex
+	// methodCounts are the number of games by their match method.
+	methodCounts = map[string]int{}
+	// storedCount is the number of games from the match database.
+	storedCount int
+)
+
+// countMethod adds n to the games matched by the method, -1 when a skip is decided again.
+func countMethod(method string, n int) {
+	statsMtx.Lock()
+	defer statsMtx.Unlock()
+	methodCounts[method] += n
+}
+
+// countStored counts a game from the match database.
+func countStored() {
+	statsMtx.Lock()
+	defer statsMtx.Unlock()
+	storedCount++
+}
+
+// writeStats prints the counters of the run to w.
+func writeStats(w io.Writer) error {
+	statsMtx.Lock()
+	defer statsMtx.Unlock()
+	st := matcher.Stats()
+	fmt.Fprintln(w, "\nstats:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, m := range statMethods {
+		fmt.Fprintf(tw, "  %s\t%d\n", m, methodCounts[m])
+	}
+	fmt.Fprintf(tw, "  from the database\t%d\n", storedCount)
+	fmt.Fprintf(tw, "  requests\t%d, %d from the cache\n", st.Requests, st.CacheHits)
+	fmt.Fprintf(tw, "  average latency\t%s\n", st.AvgLatency().Round(time.Millisecond))
+	fmt.Fprintf(tw, "  cloudflare challenges\t%d, %d pauses\n", st.Challenges, st.Pauses)
+	fmt.Fprintf(tw, "  logo searches\t%d\n", st.ImageSearches)
+	if useCatalog {
+		fmt.Fprintf(tw, "  catalog index lookups\t%d\n", st.CatalogSearches)
+	}
+	parsed := make([]string, 0, len(st.Parsed))
+	for _, p := range epicmatch.ParseStrategies() {
+		parsed = append(parsed, fmt.Sprintf("%s %d", p, st.Parsed[p]))
+	}
+	fmt.Fprintf(tw, "  search pages parsed by\t%s\n", strings.Join(parsed, ", "))
+	fmt.Fprintf(tw, "  elapsed\t%s\n", time.Since(started).Round(time.Second))
+	return tw.Flush()
+}
+
+// pushStats sends the counters of the run to the Prometheus pushgateway, replacing the ones of
+// the previous run.
+func pushStats(ctx context.Context, gateway string) error {
+	statsMtx.Lock()
+	st := matcher.Stats()
+	var b bytes.Buffer
+	b.WriteString("# TYPE epic_export_games gauge\n")
+	for _, m := range statMethods {
+		fmt.Fprintf(&b, "epic_export_games{method=%q} %d\n", m, methodCounts[m])
+	}
+	fmt.Fprintf(&b, "epic_export_games{method=\"stored\"} %d\n", storedCount)
+	b.WriteString("# TYPE epic_export_search_pages gauge\n")
+	for _, p := range epicmatch.ParseStrategies() {
+		fmt.Fprintf(&b, "epic_export_search_pages{parser=%q} %d\n", p, st.Parsed[p])
+	}
+	statsMtx.Unlock()
+	for _, g := range []struct {
+		name string
+		val  float64
+	}{
+		{"epic_export_requests", float64(st.Requests)},
+		{"epic_export_cache_hits", float64(st.CacheHits)},
+		{"epic_export_request_latency_seconds_avg", st.AvgLatency().Seconds()},
+		{"epic_export_cloudflare_challenges", float64(st.Challenges)},
+		{"epic_export_challenge_pauses", float64(st.Pauses)},
+		{"epic_export_logo_searches", float64(st.ImageSearches)},
+		{"epic_export_catalog_searches", float64(st.CatalogSearches)},
+		{"epic_export_duration_seconds", time.Since(started).Seconds()},
+		{"epic_export_last_run_timestamp_seconds", float64(time.Now().Unix())},
+	} {
+		fmt.Fprintf(&b, "# TYPE %s gauge\n%s %g\n", g.name, g.name, g.val)
+	}
+	link := strings.TrimSuffix(gateway, "/") + "/metrics/job/epic_export"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, link, &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push stats to %s: %w", link, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("wrong status for pushing stats to %s: %s", link, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"path/filepath"
+)
+
+// templateOutput writes the games by a user supplied html/template. The template is executed
+// for every result, its optional "header" and "footer" templates replace the default page
+// header and footer.
+type templateOutput struct {
+	w *bufio.Writer
+	t *template.Template
+}
+
+// newTemplateOutput parses the template file for the output writing to w.
+func newTemplateOutput(path string, w *bufio.Writer) (output, error) {
+	t, err := template.New(filepath.Base(path)).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return &templateOutput{w: w, t: t}, nil
+}
+
+// part executes the named template, or writes the default without it.
+func (o *templateOutput) part(name, def string) {
+	if o.t.Lookup(name) == nil {
+		o.w.WriteString(def)
+		return
+	}
+	if err := o.t.ExecuteTemplate(o.w, name, nil); err != nil {
+		slog.Error("failed to execute template", "template", name, "err", err)
+	}
+}
+
+func (o *templateOutput) begin() {
+	o.part("header", pageHeader())
+}
+
+func (o *templateOutput) write(r *result) {
+	if err := o.t.Execute(o.w, r); err != nil {
+		slog.Error("failed to execute template", "game", r.Name, "stage", "output", "err", err)
+		addFailure(r.Name, "output", err)
+	}
+}
+
+func (o *templateOutput) end() {
+	o.part("footer", htmlFooter)
+}
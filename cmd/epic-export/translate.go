@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// translateURL is the LibreTranslate endpoint translating the localized names to English by
+// -translate, empty without it. translateKey is its API key, if it needs any.
+var translateURL, translateKey string
+
+// translation is the response of LibreTranslate.
+type translation struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+// parseLocales returns the comma separated store locales.
+func parseLocales(list string) []string {
+	var locales []string
+	for _, l := range strings.Split(list, ",") {
+		if l = strings.TrimSpace(l); len(l) > 0 {
+			locales = append(locales, l)
+		}
+	}
+	return locales
+}
+
+// searchTranslated searches the romanized and the translated names of the game, when it's
+// localized, before choosing from the results of its name. Returns true if the game was written
+// by an exact name match.
+func (g *game) searchTranslated(ctx context.Context) bool {
+	if g.dup {
+		return false // the year couldn't be checked
+	}
+	g.stage("translate")
+	var names []string
+	if r := epicmatch.Romanize(g.Name); r != g.Name {
+		names = append(names, r)
+	}
+	if len(translateURL) > 0 {
+		t, err := translate(ctx, g.Name)
+		if err != nil {
+			g.log.Warn("failed to translate name", "err", err)
+		} else if !epicmatch.SameName(t, g.Name) && !epicmatch.SameName(t, epicmatch.Romanize(g.Name)) {
+			names = append(names, t)
+		}
+	}
+	for _, name := range names {
+		matches, err := matcher.Search(ctx, name)
+		if err != nil {
+			g.log.Debug("no search result of localized name", "name", name, "err", err)
+			continue
+		}
+		for _, m := range matches {
+			if epicmatch.SameName(m.Name, name) {
+				g.log.Info("localized name matches", "name", name, "match", m.Name)
+				m.Confidence = 100
+				g.writeMatch(ctx, methodSearch, &m)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// translate returns the name in English by LibreTranslate, the source language being detected.
+func translate(ctx context.Context, name string) (string, error) {
+	body, err := json.Marshal(map[string]string{"q": name, "source": "auto", "target": "en", "format": "text",
+		"api_key": translateKey})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(translateURL, "/")+"/translate",
+		bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("content-type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var t translation
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", fmt.Errorf("failed to decode translation: %s: %w", resp.Status, err)
+	}
+	if resp.StatusCode >= 400 || len(t.Error) > 0 {
+		return "", fmt.Errorf("%s: %s", resp.Status, t.Error)
+	}
+	if t.TranslatedText = strings.TrimSpace(t.TranslatedText); len(t.TranslatedText) == 0 {
+		return "", errors.New("empty translation")
+	}
+	return t.TranslatedText, nil
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	_ "golang.org/x/image/webp"
+	"golang.org/x/term"
+)
+
+const (
+	logLines   = 5   // number of log lines shown at the bottom
+	logQueue   = 256 // number of log lines waiting for the terminal UI before dropping them
+	listLines  = 15  // number of choices shown at once
+	logoWidth  = 40  // width of the rendered logo in terminal cells
+	logoHeight = 20  // maximum height of the rendered logo in terminal cells
+	thumbWidth = 24  // width of the rendered thumbnail of the choice under the cursor
+	thumbLines = 8   // maximum height of the rendered thumbnail
+	logoSlot   = 0   // image slot of the logo, see previews
+	thumbSlot  = 1   // image slot of the thumbnail
+)
+
+var (
+	// ui is the terminal UI, nil when not running in a terminal.
+	ui *tea.Program
+	// uiRunning is false before starting and after quitting the terminal UI.
+	uiRunning atomic.Bool
+	// uiDone is closed when the terminal UI stopped.
+	uiDone = make(chan struct{})
+	// uiLogs are the log lines waiting for the terminal UI, and droppedLogs counts the ones not
+	// fitting. uiLogMtx guards switching the log lines between the UI and stderr.
+	uiLogs      = make(chan string, logQueue)
+	droppedLogs atomic.Int64
+	uiLogMtx    sync.Mutex
+	// redos keeps track of skipped games being decided again.
+	redos sync.WaitGroup
+	// skips are the skipped games the user may decide again, passed on by emitSkips after the UI
+	// quit, so the hooks don't run for the ones decided again.
+	skips   []*game
+	skipMtx sync.Mutex
+
+	errNoTerminal = errors.New("not running in a terminal")
+	errQuit       = errors.New("user quit")
+
+	cursorStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// prompt is a question for the user with a list of choices.
+type prompt struct {
+	name    string // game name for the queue of prompts
+	title   string
+	logo    string // logo URL to show next to the choices
+	choices []string
+	// images are the store thumbnails of the choices for the web UI, if any.
+	images []string
+	// inputs are the choices asking for typing a text.
+	inputs map[string]textInput
+	reply  chan answer
+}
+
+// textInput is what a choice of the prompt asks to type.
+type textInput struct {
+	title, placeholder string
+	value              string // initial text to edit
+}
+
+type answer struct {
+	choice string
+	index  int
+	text   string // typed text for the input choice
+}
+
+// skipped is a game the user skipped, it can be decided again before quitting.
+type skipped struct {
+	name string
+	redo func()
+}
+
+type (
+	logMsg      string
+	progressMsg struct{}
+	finishedMsg struct{}
+	redoneMsg   struct{}
+	sessionMsg  struct{}
+	logoMsg     struct{ key, img string }
+	// pauseMsg redraws the countdown of the pause of the store requests.
+	pauseMsg struct{}
+)
+
+// runUI shows the terminal UI until the user quits, or just waits for done without a terminal.
+// Quitting early cancels the run, and the UI quits when the context is done.
+func runUI(ctx context.Context, cancel context.CancelFunc, total int, done <-chan struct{}) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		printProgress(total, done)
+		return
+	}
+	input := textinput.New()
+	ui = tea.NewProgram(&tuiModel{
+		ctx:      ctx,
+		total:    total,
+		input:    input,
+		progress: progress.New(progress.WithDefaultGradient()),
+		logos:    map[string]string{},
+	}, tea.WithAltScreen())
+	go func() {
+		select {
+		case <-done:
+			ui.Send(finishedMsg{})
+		case <-ctx.Done():
+			ui.Quit()
+		}
+	}()
+	go func() {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if len(pauseLine()) > 0 {
+					ui.Send(pauseMsg{})
+				}
+			case <-uiDone:
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case l := <-uiLogs:
+				ui.Send(logMsg(l))
+			case <-uiDone:
+				return
+			}
+		}
+	}()
+	uiRunning.Store(true)
+	_, err := ui.Run()
+	stopUILogs()
+	close(uiDone)
+	cancel()
+	if err != nil {
+		slog.Error("terminal UI failed", "err", err)
+	}
+}
+
+// stopUILogs switches the log lines back to stderr, writing the ones not shown by the UI yet.
+func stopUILogs() {
+	uiLogMtx.Lock()
+	defer uiLogMtx.Unlock()
+	uiRunning.Store(false)
+	for {
+		select {
+		case l := <-uiLogs:
+			fmt.Fprintln(os.Stderr, l)
+		default:
+			if n := droppedLogs.Load(); n > 0 {
+				fmt.Fprintf(os.Stderr, "%d log lines were dropped by the terminal UI, see -log-file for all\n", n)
+			}
+			return
+		}
+	}
+}
+
+// ask shows the prompt to the user and waits for the answer, in the web UI if it's running.
+func ask(ctx context.Context, p *prompt) (answer, error) {
+	if webUI != nil {
+		return webUI.ask(ctx, p)
+	}
+	if !uiRunning.Load() {
+		return answer{}, errNoTerminal
+	}
+	p.reply = make(chan answer, 1)
+	ui.Send(p)
+	select {
+	case a := <-p.reply:
+		return a, nil
+	case <-uiDone:
+		return answer{}, errQuit
+	case <-sessionSet:
+		return answer{}, errSession
+	case <-ctx.Done():
+		return answer{}, ctx.Err()
+	}
+}
+
+// uiProgress counts a processed game.
+func uiProgress() {
+	resolved.Add(1)
+	if uiRunning.Load() {
+		ui.Send(progressMsg{})
+	}
+}
+
+// uiSkipped lets the user decide again on the skipped game before quitting. Returns false
+// without the terminal UI, then the game is to be emitted as skipped right away.
+func uiSkipped(ctx context.Context, g *game) bool {
+	if !uiRunning.Load() {
+		return false
+	}
+	c := g.clone()
+	skipMtx.Lock()
+	skips = append(skips, c)
+	skipMtx.Unlock()
+	ui.Send(skipped{name: g.Name, redo: func() {
+		skipMtx.Lock()
+		skips = slices.DeleteFunc(skips, func(s *game) bool { return s == c })
+		skipMtx.Unlock()
+		dropSkip(c.Name)
+		countMethod(methodSkip, -1)
+		if err := c.pick(ctx); err != nil {
+			c.log.Error("pick failed", "err", err)
+			addFailure(c.Name, "pick", err)
+		}
+	}})
+	return true
+}
+
+// emitSkips emits the skipped games that weren't decided again in the terminal UI.
+func emitSkips() {
+	skipMtx.Lock()
+	defer skipMtx.Unlock()
+	for _, g := range skips {
+		emitSkipped(g)
+	}
+	skips = nil
+}
+
+type tuiModel struct {
+	ctx         context.Context
+	total, done int
+	width       int  // width of the terminal, 0 before knowing it
+	finished    bool // all games were processed
+	redoing     int  // number of skipped games being decided again
+	queue       []*prompt
+	cursor      int
+	typing      bool
+	input       textinput.Model
+	skipped     []skipped
+	showSkipped bool
+	skipCursor  int
+	progress    progress.Model
+	logs        []string
+	logos       map[string]string // rendered logos and thumbnails by logoKey
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.progress.Width = min(msg.Width-4, 80)
+		m.width = msg.Width
+	case tea.KeyMsg:
+		return m.key(msg)
+	case logMsg:
+		m.logs = append(m.logs, string(msg))
+		if len(m.logs) > logLines {
+			m.logs = m.logs[len(m.logs)-logLines:]
+		}
+	case progressMsg:
+		m.done++
+	case finishedMsg:
+		m.finished = true
+		return m, m.quitIfDone()
+	case redoneMsg:
+		m.redoing--
+		return m, m.quitIfDone()
+	case sessionMsg:
+		// the waiting prompts are decided for all
+		m.queue, m.typing = nil, false
+		return m, m.next()
+	case skipped:
+		m.skipped = append(m.skipped, msg)
+	case logoMsg:
+		m.logos[msg.key] = msg.img
+	case *prompt:
+		m.queue = append(m.queue, msg)
+		if len(m.queue) == 1 {
+			return m, m.next()
+		}
+	}
+	return m, nil
+}
+
+// key handles key presses for typing, picking and browsing skipped games.
+func (m *tuiModel) key(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.typing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.typing = false
+			return m, m.reply(answer{text: m.input.Value()})
+		case tea.KeyEsc:
+			m.typing = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "s":
+		m.showSkipped = !m.showSkipped && len(m.skipped) > 0
+		m.skipCursor = 0
+	case "up", "k":
+		if m.showSkipped {
+			m.skipCursor = max(m.skipCursor-1, 0)
+		} else if len(m.queue) > 0 {
+			m.cursor = max(m.cursor-1, 0)
+			return m, m.thumbnail()
+		}
+	case "down", "j":
+		if m.showSkipped {
+			m.skipCursor = min(m.skipCursor+1, len(m.skipped)-1)
+		} else if len(m.queue) > 0 {
+			m.cursor = min(m.cursor+1, len(m.queue[0].choices)-1)
+			return m, m.thumbnail()
+		}
+	case "enter":
+		if m.showSkipped {
+			return m, m.redo()
+		}
+		if len(m.queue) == 0 {
+			return m, nil
+		}
+		if in, ok := m.queue[0].inputs[m.queue[0].choices[m.cursor]]; ok {
+			m.typing = true
+			m.input.Placeholder = in.placeholder
+			m.input.SetValue(in.value)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+		return m, m.reply(answer{})
+	}
+	return m, nil
+}
+
+// reply answers the current prompt with the choice under the cursor, and shows the next one.
+func (m *tuiModel) reply(a answer) tea.Cmd {
+	p := m.queue[0]
+	a.index, a.choice = m.cursor, p.choices[m.cursor]
+	p.reply <- a
+	m.queue = m.queue[1:]
+	return m.next()
+}
+
+// next prepares showing the first prompt of the queue.
+func (m *tuiModel) next() tea.Cmd {
+	m.cursor = 0
+	if len(m.queue) == 0 {
+		return m.quitIfDone()
+	}
+	return tea.Batch(m.fetchLogo(m.queue[0].logo, logoWidth, logoHeight, logoSlot), m.thumbnail())
+}
+
+// thumbnail prepares showing the store thumbnail of the choice under the cursor, if any.
+func (m *tuiModel) thumbnail() tea.Cmd {
+	if len(m.queue) == 0 {
+		return nil
+	}
+	if p := m.queue[0]; m.cursor < len(p.images) {
+		return m.fetchLogo(p.images[m.cursor], thumbWidth, thumbLines, thumbSlot)
+	}
+	return nil
+}
+
+// logoKey is the key of the rendered image of the link in the slot.
+func logoKey(link string, slot int) string {
+	return fmt.Sprintf("%d %s", slot, link)
+}
+
+// fetchLogo renders the image of the link in the background, if it's not rendered yet.
+func (m *tuiModel) fetchLogo(link string, width, maxRows, slot int) tea.Cmd {
+	key := logoKey(link, slot)
+	if _, ok := m.logos[key]; ok || len(link) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		img, err := renderLogo(m.ctx, link, width, maxRows, slot)
+		if err != nil {
+			img = dimStyle.Render("no image")
+		}
+		return logoMsg{key: key, img: img}
+	}
+}
+
+// redo starts deciding again the skipped game under the cursor.
+func (m *tuiModel) redo() tea.Cmd {
+	s := m.skipped[m.skipCursor]
+	m.skipped = slices.Delete(m.skipped, m.skipCursor, m.skipCursor+1)
+	m.showSkipped = false
+	m.redoing++
+	redos.Add(1)
+	return func() tea.Msg {
+		defer redos.Done()
+		s.redo()
+		return redoneMsg{}
+	}
+}
+
+// quitIfDone stops the UI when there's nothing left to decide.
+func (m *tuiModel) quitIfDone() tea.Cmd {
+	if m.finished && m.redoing == 0 && len(m.queue) == 0 && len(m.skipped) == 0 {
+		return tea.Quit
+	}
+	return nil
+}
+
+func (m *tuiModel) View() string {
+	var sb strings.Builder
+	percent := 1.0
+	if m.total > 0 {
+		percent = float64(m.done) / float64(m.total)
+	}
+	fmt.Fprintf(&sb, "%s %s, %d waiting for you, %d skipped\n\n",
+		m.progress.ViewAs(percent), progressLine(m.done, m.total), len(m.queue), len(m.skipped))
+	help := "↑/↓ move • enter pick • s skipped games • q quit"
+	switch {
+	case m.showSkipped:
+		sb.WriteString("skipped games, pick one to decide again:\n")
+		for i, s := range m.skipped {
+			sb.WriteString(choiceLine(s.name, i == m.skipCursor))
+		}
+		help = "↑/↓ move • enter decide again • s back • q quit"
+	case len(m.queue) > 0:
+		sb.WriteString(m.promptView())
+		if m.typing {
+			help = "enter done • esc back"
+		}
+	case m.finished:
+		sb.WriteString("all games processed, decide again on skipped games or quit\n")
+	default:
+		sb.WriteString("searching...\n")
+	}
+	if len(m.queue) > 1 {
+		names := make([]string, 0, len(m.queue)-1)
+		for _, p := range m.queue[1:] {
+			names = append(names, p.name)
+		}
+		fmt.Fprintf(&sb, "\nnext: %s\n", strings.Join(names, ", "))
+	}
+	sb.WriteString("\n")
+	// long lines are cut, so wrapping doesn't push the picker off the screen
+	logStyle := dimStyle
+	if m.width > 0 {
+		logStyle = dimStyle.MaxWidth(m.width)
+	}
+	for _, l := range m.logs {
+		sb.WriteString(logStyle.Render(l) + "\n")
+	}
+	sb.WriteString("\n" + dimStyle.Render(help))
+	return sb.String()
+}
+
+// promptView shows the current prompt with the logo on the left and a window of choices on the right.
+func (m *tuiModel) promptView() string {
+	p := m.queue[0]
+	var sb strings.Builder
+	sb.WriteString(p.title + "\n")
+	first := min(max(m.cursor-listLines/2, 0), max(len(p.choices)-listLines, 0))
+	for i := first; i < min(first+listLines, len(p.choices)); i++ {
+		sb.WriteString(choiceLine(p.choices[i], i == m.cursor))
+	}
+	if m.typing {
+		sb.WriteString("\n" + p.inputs[p.choices[m.cursor]].title + "\n" + m.input.View() + "\n")
+	}
+	logo, ok := m.logos[logoKey(p.logo, logoSlot)]
+	if !ok {
+		logo = dimStyle.Render("loading logo...")
+	}
+	if m.cursor < len(p.images) && len(p.images[m.cursor]) > 0 {
+		thumb, ok := m.logos[logoKey(p.images[m.cursor], thumbSlot)]
+		if !ok {
+			thumb = dimStyle.Render("loading thumbnail...")
+		}
+		logo = lipgloss.JoinVertical(lipgloss.Left, logo, dimStyle.Render("store thumbnail:"), thumb)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, logo, "  ", sb.String())
+}
+
+func choiceLine(s string, selected bool) string {
+	if selected {
+		return cursorStyle.Render("> "+s) + "\n"
+	}
+	return "  " + s + "\n"
+}
+
+// renderLogo downloads the image and renders it by the -preview renderer, in width columns and at
+// most maxRows lines.
+func renderLogo(ctx context.Context, link string, width, maxRows, slot int) (string, error) {
+	body, err := matcher.Get(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	img, _, err := image.Decode(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode logo %s: %w", link, err)
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return "", fmt.Errorf("empty logo %s", link)
+	}
+	cols, rows := imageBox(b, width, maxRows)
+	return previews[preview](img, cols, rows, slot), nil
+}
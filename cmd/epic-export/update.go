@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// writtenReaders return the names of the games in an existing output by the format.
+var writtenReaders = map[string]func(r io.Reader) ([]string, error){
+	"html":     htmlNames,
+	"md":       mdNames,
+	"csv":      csvNames,
+	"json":     jsonNames,
+	"obsidian": obsidianNames,
+	"opml":     opmlNames,
+}
+
+// writtenNames returns the normalized names of the games in the output file of the format, for
+// skipping them in the update.
+func writtenNames(path, format string) (map[string]bool, error) {
+	read, ok := writtenReaders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	names, err := read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read games of %s: %w", path, err)
+	}
+	written := make(map[string]bool, len(names))
+	for _, n := range names {
+		written[normName(n)] = true
+	}
+	return written, nil
+}
+
+// htmlNames returns the names of the cards, the text of their first element.
+func htmlNames(r io.Reader) ([]string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.DataAtom == atom.Div {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode {
+					if c.DataAtom == atom.A || c.DataAtom == atom.Span {
+						names = append(names, nodeText(c))
+					}
+					break
+				}
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return names, nil
+}
+
+// nodeText returns the text content of the node.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// mdNames returns the names of the table rows after the header.
+func mdNames(r io.Reader) ([]string, error) {
+	var names []string
+	sc := bufio.NewScanner(r)
+	for i := 0; sc.Scan(); i++ {
+		if i < 2 {
+			continue // header and separator
+		}
+		name, _, ok := strings.Cut(strings.TrimPrefix(sc.Text(), "| "), " | ![logo](")
+		if !ok {
+			continue
+		}
+		if link := strings.LastIndex(name, "]("); strings.HasPrefix(name, "[") && link > 0 {
+			name = name[1:link]
+		}
+		names = append(names, strings.ReplaceAll(name, `\|`, "|"))
+	}
+	return names, sc.Err()
+}
+
+// csvNames returns the name column of the records after the header.
+func csvNames(r io.Reader) ([]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i, rec := range records {
+		if i > 0 && len(rec) > 0 {
+			names = append(names, rec[0])
+		}
+	}
+	return names, nil
+}
+
+// jsonNames returns the names of the results.
+func jsonNames(r io.Reader) ([]string, error) {
+	var res []result
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(res))
+	for i, r := range res {
+		names[i] = r.Name
+	}
+	return names, nil
+}
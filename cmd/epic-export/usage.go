@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// statsEndpoint is the address the anonymous usage stats of the run are posted to by
+// -stats-endpoint, empty without it.
+var statsEndpoint string
+
+// usageStats are the aggregate counts of a run by the matching strategies, without any names,
+// links or other details of the inputs and the decisions.
+type usageStats struct {
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Games   int    `json:"games"`
+	// Methods are the resolved games by their match methods, Stored the ones of the match database.
+	Methods map[string]int `json:"methods"`
+	Stored  int            `json:"stored"`
+	// Failures are the failed lookups by their stage, then their kind, like search and parse failure.
+	Failures map[string]map[string]int `json:"failures"`
+	// Parsed are the search pages by the strategy parsing their results.
+	Parsed     map[string]int `json:"parsed"`
+	Requests   int            `json:"requests"`
+	CacheHits  int            `json:"cacheHits"`
+	Challenges int            `json:"challenges"`
+	Pauses     int            `json:"pauses"`
+	// LatencyMs is the average latency of the store requests.
+	LatencyMs int64   `json:"latencyMs"`
+	Duration  float64 `json:"durationSeconds"`
+	Finished  string  `json:"finished"`
+}
+
+// collectUsage returns the usage stats of the run of the games.
+func collectUsage(games int) usageStats {
+	st := matcher.Stats()
+	u := usageStats{Version: currentVersion(), OS: runtime.GOOS, Arch: runtime.GOARCH, Games: games,
+		Methods: map[string]int{}, Failures: map[string]map[string]int{}, Parsed: map[string]int{},
+		Requests: st.Requests, CacheHits: st.CacheHits, Challenges: st.Challenges, Pauses: st.Pauses,
+		LatencyMs: st.AvgLatency().Milliseconds(), Duration: time.Since(started).Seconds(),
+		Finished: time.Now().UTC().Truncate(time.Hour).Format(time.RFC3339)}
+	statsMtx.Lock()
+	for _, m := range statMethods {
+		u.Methods[m] = methodCounts[m]
+	}
+	u.Stored = storedCount
+	statsMtx.Unlock()
+	failMtx.Lock()
+	for _, f := range failures {
+		if u.Failures[f.Stage] == nil {
+			u.Failures[f.Stage] = map[string]int{}
+		}
+		u.Failures[f.Stage][f.Kind]++
+	}
+	failMtx.Unlock()
+	for _, p := range epicmatch.ParseStrategies() {
+		u.Parsed[p] = st.Parsed[p]
+	}
+	return u
+}
+
+// postUsage posts the usage stats of the run of the games to the endpoint as JSON, for the
+// maintainers of a community instance to see the strategies degrading.
+func postUsage(ctx context.Context, endpoint string, games int) error {
+	b, err := json.Marshal(collectUsage(games))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post stats to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("wrong status for posting stats to %s: %s", endpoint, resp.Status)
+	}
+	return nil
+}
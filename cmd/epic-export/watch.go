@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// watchDebounce is the quiet time after the last change of the inputs before running again, as
+	// export scripts write their files in many steps.
+	watchDebounce = 2 * time.Second
+	// watchStop is the time given to an interrupted run to write its results before it's killed.
+	watchStop = 30 * time.Second
+)
+
+// watch runs the export in a child process with the same arguments, then again after every change
+// of the input files until the context is done. The match database keeps the runs incremental,
+// only the new and changed games are resolved again.
+func watch(ctx context.Context, review bool, input []string) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	watched := map[string]bool{}
+	for _, path := range input {
+		if pre, rest, ok := strings.Cut(path, ":"); ok && inputs[pre] != nil {
+			path = rest
+		}
+		if len(path) == 0 || path == stdio {
+			continue // accounts and stdin have no files
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return err
+		}
+		watched[path] = true
+		// files are often replaced by renaming, so their directories are watched
+		dir := path
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		if err = w.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	if len(watched) == 0 {
+		return errors.New("no input files to watch")
+	}
+
+	// neither the command line nor the config file may start watching in the child
+	args := []string{"-watch=false"}
+	for _, arg := range os.Args[1:] {
+		if name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "="); name != "watch" || !strings.HasPrefix(arg, "-") {
+			args = append(args, arg)
+		}
+	}
+	if review {
+		args = append([]string{reviewCmd}, args...)
+	}
+	for {
+		if err = runChild(ctx, args); err != nil && ctx.Err() == nil {
+			slog.Error("export failed, waiting for the next change", "err", err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		slog.Info("watching the inputs for changes")
+		if err = waitChange(ctx, w, watched); err != nil || ctx.Err() != nil {
+			return err
+		}
+		slog.Info("inputs changed, exporting again")
+	}
+}
+
+// runChild runs this executable with the arguments on the same terminal. Interrupts reach the
+// child by the terminal too, but it's interrupted also when the context is done.
+func runChild(ctx context.Context, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = watchStop
+	return cmd.Run()
+}
+
+// waitChange returns after the watched paths are changed, and stay unchanged for watchDebounce.
+// Changes during the previous run count too.
+func waitChange(ctx context.Context, w *fsnotify.Watcher, watched map[string]bool) error {
+	var quiet <-chan time.Time
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return errors.New("input watcher closed")
+			}
+			if ev.Op == fsnotify.Chmod || !watched[ev.Name] && !watched[filepath.Dir(ev.Name)] {
+				continue
+			}
+			quiet = time.After(watchDebounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return errors.New("input watcher closed")
+			}
+			slog.Warn("input watcher error", "err", err)
+		case <-quiet:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
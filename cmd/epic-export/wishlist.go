@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// Modes of the run by -mode: the games owned, or also the games wanted.
+const (
+	modeLibrary  = "library"
+	modeWishlist = "wishlist"
+)
+
+// wishFmt is a game card of the wishlist page.
+const wishFmt = `<div><a href="%s">%s</a>%s%s<br/><img loading="lazy" src="%s"</img></div>
+`
+
+var (
+	// wishMode is set by -mode wishlist, writing the flagged and unmatched games to wishlistPath,
+	// HTML or JSON for .json.
+	wishMode     bool
+	wishlistPath string
+	wishMtx      sync.Mutex
+	wishes       []*result
+)
+
+// wishItem is a game of the JSON wishlist.
+type wishItem struct {
+	Name string `json:"name"`
+	Logo string `json:"logo"`
+	// Link is the store page of a flagged game, if it's matched.
+	Link string `json:"link,omitempty"`
+	// SearchLink is the Epic search page of the name.
+	SearchLink string           `json:"searchLink"`
+	Price      *epicmatch.Price `json:"price,omitempty"`
+	// Flagged is true for the games flagged as wanted by the input, false for the unmatched ones.
+	Flagged bool `json:"flagged"`
+}
+
+// wanted returns if the result is of a game flagged as wanted in wishlist mode. It isn't owned,
+// so it's written only to the wishlist.
+func wanted(r *result) bool {
+	return wishMode && r.wishlist
+}
+
+// addWish keeps the result for the wishlist, if it's wanted or unmatched in wishlist mode.
+func addWish(r *result) {
+	if !wishMode || !r.wishlist && len(r.Link) > 0 {
+		return
+	}
+	wishMtx.Lock()
+	defer wishMtx.Unlock()
+	wishes = append(wishes, r)
+}
+
+// writeWishlist writes the wished games in the order of the output to wishlistPath, as a page
+// of their cards linked to the store or its search, or JSON for .json.
+func writeWishlist(order string) error {
+	wishMtx.Lock()
+	defer wishMtx.Unlock()
+	sortResults(order, wishes)
+	items := make([]wishItem, len(wishes))
+	for i, r := range wishes {
+		items[i] = wishItem{Name: r.Name, Logo: r.Logo, SearchLink: matcher.SearchLink(r.Name), Price: r.Price,
+			Flagged: r.wishlist}
+		if r.wishlist {
+			items[i].Link = r.Link
+		}
+	}
+	if strings.EqualFold(filepath.Ext(wishlistPath), ".json") {
+		b, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(wishlistPath, append(b, '\n'), 0644)
+	}
+	var b strings.Builder
+	b.WriteString(pageHeader())
+	fmt.Fprintf(&b, sectionFmt, html.EscapeString(text.wishlist))
+	for _, it := range items {
+		link, badge := it.Link, ""
+		if len(link) == 0 {
+			link = it.SearchLink
+		}
+		if !it.Flagged {
+			badge = fmt.Sprintf(`<span class="source">%s</span>`, html.EscapeString(text.unmatched))
+		}
+		fmt.Fprintf(&b, wishFmt, html.EscapeString(localLink(link)), html.EscapeString(it.Name), badge,
+			priceHTML(it.Price), html.EscapeString(it.Logo))
+	}
+	b.WriteString(sectionEnd + htmlFooter)
+	return os.WriteFile(wishlistPath, []byte(b.String()), 0644)
+}
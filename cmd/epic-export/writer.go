@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// orders are the possible orders of the written results, see writeResults.
+var orders = []string{"input", "alpha", "playtime", "resolved"}
+
+// results are sent by the workers to the only goroutine writing the output, so the fragments
+// never interleave.
+var results chan *result
+
+var (
+	// outFile is the temporary output file under writer, for the checkpoints, nil for stdout.
+	outFile *os.File
+	// flushEvery is the number of results between checkpoints, 0 writes only at the end.
+	flushEvery int
+)
+
+// writeResults writes the results until the channel is closed, then closes done. Results are
+// written as they come in resolved order, streamed to stdout, otherwise they're sorted at the end
+// by the input order or alphabetically. Every flushEvery results a checkpoint makes the file a valid
+// document of the results so far, in sorted orders by writing them all again.
+func writeResults(order string, done chan<- struct{}) {
+	defer close(done)
+	var base int64
+	err := writer.Flush()
+	switch {
+	case outFile == nil:
+		flushEvery = 0 // stdout can't be rewritten, the resolved order streams the results instead
+	case err == nil:
+		base, err = outFile.Seek(0, io.SeekCurrent)
+	}
+	if err != nil {
+		slog.Error("failed to get output position, checkpoints are disabled", "err", err)
+		flushEvery = 0
+	} else if flushEvery > 0 {
+		checkpoint() // valid before the first results too
+	}
+	var count int
+	jo, _ := out.(*jsonOutput)
+	if jo != nil {
+		count = jo.count
+	}
+	ho, _ := out.(*htmlOutput)
+
+	var n int
+	if order == "resolved" {
+		for r := range results {
+			out.write(r)
+			n++
+			switch {
+			case outFile == nil:
+				if err := writer.Flush(); err != nil {
+					slog.Error("failed to write result", "err", err)
+				}
+			case flushEvery > 0 && n%flushEvery == 0:
+				checkpoint()
+			}
+		}
+		return
+	}
+	var all []*result
+	rewrite := func() {
+		sortResults(order, all)
+		groupResults(all)
+		if jo != nil {
+			jo.count = count
+		}
+		if ho != nil {
+			ho.inSection = false
+			ho.anchors.reset()
+		}
+		for _, r := range all {
+			out.write(r)
+		}
+	}
+	for r := range results {
+		all = append(all, r)
+		if n++; flushEvery > 0 && n%flushEvery == 0 {
+			if _, err := outFile.Seek(base, io.SeekStart); err != nil {
+				slog.Error("failed to rewind output", "err", err)
+				continue
+			}
+			rewrite()
+			checkpoint()
+		}
+	}
+	if flushEvery > 0 && n >= flushEvery {
+		if _, err := outFile.Seek(base, io.SeekStart); err != nil {
+			slog.Error("failed to rewind output", "err", err)
+			return
+		}
+	}
+	rewrite()
+}
+
+// sortResults sorts the results by the input order, alphabetically or by the playtime, the most
+// played first and the rest in the input order.
+func sortResults(order string, all []*result) {
+	switch order {
+	case "alpha":
+		slices.SortStableFunc(all, func(a, b *result) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+	case "playtime":
+		slices.SortStableFunc(all, func(a, b *result) int {
+			if a.Playtime != b.Playtime {
+				return b.Playtime - a.Playtime
+			}
+			return a.index - b.index
+		})
+	default:
+		slices.SortStableFunc(all, func(a, b *result) int { return a.index - b.index })
+	}
+}
+
+// checkpoint writes the results so far and the tail of the output, then moves back before the
+// tail, so the next results overwrite it. A crash leaves a valid, browsable partial document.
+func checkpoint() {
+	if err := writer.Flush(); err != nil {
+		slog.Error("failed to write result file", "err", err)
+		return
+	}
+	pos, err := outFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		slog.Error("failed to get output position", "err", err)
+		return
+	}
+	out.end()
+	if err = writer.Flush(); err == nil {
+		var end int64
+		if end, err = outFile.Seek(0, io.SeekCurrent); err == nil {
+			err = outFile.Truncate(end)
+		}
+	}
+	if err != nil {
+		slog.Error("failed to write output checkpoint", "err", err)
+	}
+	if _, err = outFile.Seek(pos, io.SeekStart); err != nil {
+		slog.Error("failed to rewind output", "err", err)
+	}
+}
+
+// replaceOutput closes the temporary output, and renames it to the output path, keeping the
+// previous output as .bak when the results were merged into it. The output of an interrupted run
+// is renamed to .partial instead if there's a previous output, leaving it as it was.
+func replaceOutput(path string, merged, interrupted bool) error {
+	tmp := outFile.Name()
+	if err := outFile.Close(); err != nil {
+		return err
+	}
+	mode := fs.FileMode(0644)
+	fi, err := os.Stat(path)
+	if err == nil {
+		mode = fi.Mode().Perm()
+	}
+	previous := err == nil
+	if err := os.Chmod(tmp, mode); err != nil {
+		return err
+	}
+	if interrupted && previous {
+		if err := os.Rename(tmp, path+".partial"); err != nil {
+			return err
+		}
+		slog.Warn("the run was interrupted, the previous output is kept", "partial", path+".partial")
+		return nil
+	}
+	if merged {
+		if err := os.Rename(path, path+".bak"); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/vendelin8/epic-export/pkg/epicmatch"
+)
+
+// maxYearChecks is the maximum number of search results of a game whose release year is checked.
+const maxYearChecks = 5
+
+var reYear = regexp.MustCompile(`\b(19|20)\d\d\b`)
+
+// parseYear returns the first year in the text, like a date or a plain year, 0 without any.
+func parseYear(s string) int {
+	y, _ := strconv.Atoi(reYear.FindString(s))
+	return y
+}
+
+// dbKey returns the key of the game in the match database, the name with the release year for
+// games of the same name.
+func (g *game) dbKey() string {
+	if g.dup {
+		return fmt.Sprintf("%s (%d)", g.Name, g.Year)
+	}
+	return g.Name
+}
+
+// released returns the release year of the product page, 0 if it's unknown.
+func (g *game) released(ctx context.Context, link string) int {
+	md, err := matcher.Metadata(ctx, link)
+	if err != nil {
+		g.log.Debug("no release date", "link", link, "err", err)
+		return 0
+	}
+	return parseYear(md.Released)
+}
+
+// byYear returns the only one of the results named like the game, editions aside, that was
+// released in the year of the input. It's nil without the year of the game, or if it doesn't tell
+// the results apart.
+func (g *game) byYear(ctx context.Context, matches []epicmatch.Match) *epicmatch.Match {
+	if g.Year == 0 {
+		return nil
+	}
+	base := (&epicmatch.Match{Name: g.Name}).Base()
+	var found *epicmatch.Match
+	checked := 0
+	for i, m := range matches {
+		if len(m.Store) > 0 || !epicmatch.IsProduct(m.Link) || m.Base() != base {
+			continue
+		}
+		if checked++; checked > maxYearChecks {
+			break
+		}
+		if g.released(ctx, m.Link) != g.Year {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = &matches[i]
+	}
+	if found != nil {
+		g.log.Info("told apart by release year", "match", found.Name, "year", g.Year)
+	}
+	return found
+}
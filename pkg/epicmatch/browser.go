@@ -0,0 +1,158 @@
+package epicmatch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cdpnet "github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// browserTimeout is the maximum time of a page in the headless browser without Config.Timeout,
+	// passing the Cloudflare challenge included.
+	browserTimeout = time.Minute
+	// challengePoll is the interval of checking if the page passed the Cloudflare challenge.
+	challengePoll = 500 * time.Millisecond
+)
+
+// pageStateJS returns the ready state and the title of the page.
+const pageStateJS = `[document.readyState, document.title]`
+
+// challengeTitle is the title of the Cloudflare challenge page, see retryB.
+const challengeTitle = "Just a moment..."
+
+// browserFetcher gets the store pages by a headless Chromium running their JavaScript, so the
+// Cloudflare challenge passes like in a desktop browser. Other links are fetched as usual.
+type browserFetcher struct {
+	c *Client
+	// browser is the context of the browser process, each page is a new tab of it.
+	browser context.Context
+	cancel  context.CancelFunc
+	once    sync.Once
+	err     error
+}
+
+// newBrowserFetcher returns the fetcher of the browser of Config.BrowserPath, or the Chrome or
+// Chromium found on the system. The browser is started by the first store page.
+func newBrowserFetcher(c *Client) *browserFetcher {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.UserAgent(currentBrowser().agent),
+		// not telling the site it's automated
+		chromedp.Flag("enable-automation", false),
+		chromedp.Flag("disable-blink-features", "AutomationControlled"))
+	if len(c.cfg.BrowserPath) > 0 {
+		opts = append(opts, chromedp.ExecPath(c.cfg.BrowserPath))
+	}
+	if len(c.cfg.Proxies) > 0 {
+		// the browser has a single proxy, the first one
+		opts = append(opts, chromedp.ProxyServer(c.cfg.Proxies[0]))
+	}
+	if os.Geteuid() == 0 {
+		// like in a container, where Chromium refuses to run its sandbox as root
+		opts = append(opts, chromedp.NoSandbox)
+	}
+	alloc, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	browser, cancelBrowser := chromedp.NewContext(alloc)
+	return &browserFetcher{c: c, browser: browser, cancel: func() {
+		cancelBrowser()
+		cancelAlloc()
+	}}
+}
+
+// start starts the browser once, so the pages open in its tabs.
+func (b *browserFetcher) start() error {
+	b.once.Do(func() {
+		if b.err = chromedp.Run(b.browser); b.err != nil {
+			b.err = fmt.Errorf("%w, failed to start headless browser: %w", ErrUnavailable, b.err)
+		}
+	})
+	return b.err
+}
+
+func (b *browserFetcher) Fetch(ctx context.Context, link string, store bool) (io.ReadCloser, error) {
+	if !store {
+		return b.c.plainGet(ctx, link)
+	}
+	if err := b.start(); err != nil {
+		return nil, err
+	}
+	c := b.c
+	rate := c.storeRate(link)
+	if err := rate.wait(ctx); err != nil {
+		return nil, err
+	}
+	timeout := c.cfg.Timeout
+	if timeout <= 0 {
+		timeout = browserTimeout
+	}
+	tab, cancelTab := chromedp.NewContext(b.browser)
+	defer cancelTab()
+	stop := context.AfterFunc(ctx, cancelTab)
+	defer stop()
+	tctx, cancel := context.WithTimeout(tab, timeout)
+	defer cancel()
+
+	var page, title string
+	headers := cdpnet.Headers{"accept-language": c.acceptLanguage()}
+	for name, value := range c.cfg.Headers {
+		if !strings.EqualFold(name, "user-agent") {
+			headers[name] = value
+		}
+	}
+	err := chromedp.Run(tctx,
+		cdpnet.SetExtraHTTPHeaders(headers),
+		cdpnet.SetCookie(ageGateCookie[0], ageGateCookie[1]).WithDomain(".epicgames.com").WithPath("/"),
+		chromedp.Navigate(link),
+		challengePassed(&title),
+		chromedp.OuterHTML("html", &page, chromedp.ByQuery),
+	)
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case tctx.Err() != nil && (title == challengeTitle || strings.Contains(page, string(retryB))) ||
+		err == nil && len(page) == 0:
+		c.challenged(rate)
+		return nil, fmt.Errorf("%w for %s in the headless browser", ErrTooManyRetries, link)
+	case tctx.Err() != nil:
+		return nil, fmt.Errorf("%w after %s in the headless browser: %s", ErrTimeout, timeout, link)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get %s in the headless browser: %w", link, err)
+	}
+	rate.passed()
+	return io.NopCloser(strings.NewReader(page)), nil
+}
+
+// challengePassed waits for the page to pass the Cloudflare challenge, which reloads the page, so
+// the errors of evaluating during the navigation are ignored. The last title of the page is kept
+// in title, telling a timeout on the challenge from a slow page.
+func challengePassed(title *string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		for {
+			var state []string
+			if err := chromedp.Evaluate(pageStateJS, &state).Do(ctx); err == nil && len(state) == 2 {
+				*title = state[1]
+				if state[0] == "complete" && state[1] != challengeTitle {
+					return nil
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(challengePoll):
+			}
+		}
+	}
+}
+
+// Close stops the headless browser of Config.Browser, if it's running.
+func (c *Client) Close() {
+	if c.browser != nil {
+		c.browser.cancel()
+	}
+}
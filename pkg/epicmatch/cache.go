@@ -0,0 +1,121 @@
+package epicmatch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheDir returns the user's cache directory for this tool, or a temporary one if unknown.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "epic-export")
+}
+
+// cachePath returns the cache file path for the given URL.
+func (c *Client) cachePath(link string) string {
+	return filepath.Join(c.cfg.CacheDir, linkHash(link))
+}
+
+// linkHash returns the file name of the URL for storing its response.
+func linkHash(link string) string {
+	sum := sha256.Sum256([]byte(link))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheGet returns a pooled buffer with the cached response of the URL, if it isn't expired.
+func (c *Client) cacheGet(link string) (*bytes.Buffer, bool) {
+	if c.cfg.CacheTTL <= 0 {
+		return nil, false
+	}
+	path := c.cachePath(link)
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) > c.cfg.CacheTTL {
+		return nil, false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	b := getBuf(int(fi.Size()) + bytes.MinRead) // ReadFrom needs room for EOF
+	if _, err = b.ReadFrom(f); err != nil {
+		putBuf(b)
+		slog.Warn("failed to read cache", "url", link, "err", err)
+		return nil, false
+	}
+	return b, true
+}
+
+// cachePut stores the response of the URL. The file is written aside and renamed in place so that
+// concurrent readers never see a partial response.
+func (c *Client) cachePut(link string, b []byte) {
+	if c.cfg.CacheTTL <= 0 {
+		return
+	}
+	if err := c.writeCache(link, b); err != nil {
+		slog.Warn("failed to cache", "url", link, "err", err)
+	}
+}
+
+func (c *Client) writeCache(link string, b []byte) error {
+	if err := os.MkdirAll(c.cfg.CacheDir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.cfg.CacheDir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.cachePath(link))
+}
+
+// cacheReader keeps a copy of the read response body, and stores it in the cache on Close if the
+// body was read to the end.
+type cacheReader struct {
+	c    *Client
+	body io.ReadCloser
+	link string
+	buf  bytes.Buffer
+	eof  bool
+}
+
+// newCacheReader wraps the response body of the URL for caching, if enabled.
+func (c *Client) newCacheReader(link string, body io.ReadCloser) io.ReadCloser {
+	if c.cfg.CacheTTL <= 0 {
+		return body
+	}
+	return &cacheReader{c: c, body: body, link: link}
+}
+
+func (r *cacheReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.buf.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		r.eof = true
+	}
+	return n, err
+}
+
+func (r *cacheReader) Close() error {
+	if r.eof {
+		r.c.cachePut(r.link, r.buf.Bytes())
+	}
+	return r.body.Close()
+}
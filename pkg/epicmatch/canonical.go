@@ -0,0 +1,83 @@
+package epicmatch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// reEpicPage finds the locale, the kind and the slug in the path of a store page link of any form,
+// like /p/slug, /en-US/p/slug, /store/en-US/bundles/slug or the old /en-US/product/slug/home.
+var reEpicPage = regexp.MustCompile(`^(?:/store)?(?:/([a-zA-Z]{2}(?:-[a-zA-Z0-9]{2,4})?))?/(p|product|bundles)/([^/?#]+)`)
+
+// trackingParams are the query parameters only telling where a visit came from, dropped by
+// CanonicalLink. The ones ending with _ match as a prefix.
+var trackingParams = []string{"utm_", "snr", "gclid", "fbclid", "msclkid", "mc_cid", "mc_eid", "igshid", "ref",
+	"referrer", "epic_affiliate", "epic_creator_id", "epic_game_id"}
+
+// CanonicalLink returns the stable form of the link, so the outputs of two runs are the same. An
+// Epic store page of any form is the page of its slug in the locale, or without a locale prefix
+// for an empty one, without its query. Other links lose their fragment and tracking parameters,
+// and the rest of the query is sorted. A link that doesn't parse is returned as is.
+func CanonicalLink(link, locale string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || len(u.Host) == 0 {
+		return link
+	}
+	u.Scheme, u.Host, u.Fragment, u.RawFragment = strings.ToLower(u.Scheme), strings.ToLower(u.Host), "", ""
+	switch strings.TrimPrefix(u.Host, "www.") {
+	case "store.epicgames.com", "epicgames.com":
+		if m := reEpicPage.FindStringSubmatch(u.Path); m != nil {
+			kind := m[2]
+			if kind == "product" {
+				kind = "p"
+			}
+			if len(locale) > 0 {
+				return fmt.Sprintf("%s/%s/%s/%s", Host, locale, kind, m[3])
+			}
+			return fmt.Sprintf("%s/%s/%s", Host, kind, m[3])
+		}
+	}
+	q := u.Query()
+	for key := range q {
+		if slices.ContainsFunc(trackingParams, func(p string) bool {
+			return key == p || strings.HasSuffix(p, "_") && strings.HasPrefix(key, p)
+		}) {
+			q.Del(key)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// FinalLink returns the product page the store link ends up at, in the configured locale. The store
+// serves some products by several slugs, like the old one of a renamed game, and the page tells
+// the current one.
+func (c *Client) FinalLink(ctx context.Context, link string) (string, error) {
+	canonical := CanonicalLink(link, c.cfg.Locale)
+	if !IsProduct(canonical) {
+		return canonical, nil
+	}
+	buf, err := c.epicGet(ctx, c.productPage(canonical))
+	if err != nil {
+		return "", fmt.Errorf("failed to get product page %s for its final link: %w", link, err)
+	}
+	defer putBuf(buf)
+	doc, err := goquery.NewDocumentFromReader(buf)
+	if err != nil {
+		return "", fmt.Errorf("%w product page %s: %w", ErrParse, link, err)
+	}
+	final, ok := doc.Find(`link[rel="canonical"]`).First().Attr("href")
+	if !ok {
+		final, ok = doc.Find(`meta[property="og:url"]`).First().Attr("content")
+	}
+	if final = CanonicalLink(final, c.cfg.Locale); ok && IsProduct(final) {
+		return final, nil
+	}
+	return canonical, nil
+}
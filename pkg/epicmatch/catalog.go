@@ -0,0 +1,291 @@
+package epicmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// catalogAPI is the GraphQL API of the store catalog, limited like the store product pages.
+	catalogAPI = "https://graphql.epicgames.com/graphql"
+	// catalogQuery lists a page of the games of the store catalog, sorted by title so the pages
+	// don't shift while they're fetched.
+	catalogQuery = `query searchStoreQuery($category: String, $count: Int, $start: Int, $country: String!, $locale: String) {
+  Catalog { searchStore(category: $category, count: $count, start: $start, country: $country, locale: $locale, sortBy: "title", sortDir: "ASC") {
+    elements { title productSlug offerType keyImages { type url } catalogNs { mappings(pageType: "productHome") { pageSlug } } }
+    paging { total }
+  } }
+}`
+	// catalogCategories are the kinds of the catalog offers indexed, see Kind.
+	catalogCategories = "games/edition/base|games/edition|bundles/games|addons|games/demo"
+	// catalogPage is the number of offers on a page of the catalog API, catalogMaxPages limits the
+	// pages if the total is off.
+	catalogPage     = 1000
+	catalogMaxPages = 100
+	// shortlistSize is the maximum number of catalog titles shortlisted for a name, shortlistMin
+	// is the minimum similarity of their trigrams, by the Dice coefficient.
+	shortlistSize = 20
+	shortlistMin  = 0.4
+)
+
+// catalogImages are the key image types of the catalog offers used as thumbnails, in order.
+var catalogImages = []string{"Thumbnail", "OfferImageWide", "DieselStoreFrontWide", "OfferImageTall"}
+
+// CatalogEntry is a title of the store catalog.
+type CatalogEntry struct {
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+	// Type is the offer type of the store, like BASE_GAME or DLC, see Match.Type.
+	Type  string `json:"type,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// catalogFile is the title list of the catalog saved in Config.CacheDir.
+type catalogFile struct {
+	Fetched time.Time      `json:"fetched"`
+	Entries []CatalogEntry `json:"entries"`
+}
+
+// catalogResponse is the used part of a page of the catalog API.
+type catalogResponse struct {
+	Data struct {
+		Catalog struct {
+			SearchStore struct {
+				Elements []struct {
+					Title       string     `json:"title"`
+					ProductSlug string     `json:"productSlug"`
+					OfferType   string     `json:"offerType"`
+					KeyImages   []keyImage `json:"keyImages"`
+					CatalogNs   struct {
+						Mappings []struct {
+							PageSlug string `json:"pageSlug"`
+						} `json:"mappings"`
+					} `json:"catalogNs"`
+				} `json:"elements"`
+				Paging struct {
+					Total int `json:"total"`
+				} `json:"paging"`
+			} `json:"searchStore"`
+		} `json:"Catalog"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// keyImage is an image of a catalog offer by its type.
+type keyImage struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// catalogIndex is a trigram index of the titles of the store catalog.
+type catalogIndex struct {
+	entries []CatalogEntry
+	// grams are the number of distinct trigrams of the titles by entry.
+	grams []int
+	// postings are the entries having the trigram.
+	postings map[string][]int32
+}
+
+// newCatalogIndex indexes the trigrams of the normalized titles of the entries.
+func newCatalogIndex(entries []CatalogEntry) *catalogIndex {
+	ix := &catalogIndex{entries: entries, grams: make([]int, len(entries)), postings: map[string][]int32{}}
+	for i, e := range entries {
+		grams := trigrams(plainName.normalize(e.Title))
+		ix.grams[i] = len(grams)
+		for _, g := range grams {
+			ix.postings[g] = append(ix.postings[g], int32(i))
+		}
+	}
+	return ix
+}
+
+// trigrams returns the distinct trigrams of the name padded by spaces, like " ha", "had" and "es "
+// for hades.
+func trigrams(name string) []string {
+	r := []rune(" " + name + " ")
+	var grams []string
+	for i := 0; i+3 <= len(r); i++ {
+		if g := string(r[i : i+3]); !slices.Contains(grams, g) {
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
+
+// shortlist returns the entries sharing the most trigrams with the name, the most similar first.
+func (ix *catalogIndex) shortlist(name string) []CatalogEntry {
+	grams := trigrams(plainName.normalize(name))
+	shared := map[int32]int{}
+	for _, g := range grams {
+		for _, i := range ix.postings[g] {
+			shared[i]++
+		}
+	}
+	type scored struct {
+		i     int32
+		score float64
+	}
+	var found []scored
+	for i, n := range shared {
+		if score := 2 * float64(n) / float64(len(grams)+ix.grams[i]); score >= shortlistMin {
+			found = append(found, scored{i, score})
+		}
+	}
+	sort.Slice(found, func(a, b int) bool {
+		if found[a].score != found[b].score {
+			return found[a].score > found[b].score
+		}
+		return found[a].i < found[b].i
+	})
+	entries := make([]CatalogEntry, 0, min(len(found), shortlistSize))
+	for _, f := range found[:min(len(found), shortlistSize)] {
+		entries = append(entries, ix.entries[f.i])
+	}
+	return entries
+}
+
+// LoadCatalog gets the titles of the store catalog for SearchCatalog, from the file saved in
+// Config.CacheDir if it's younger than Config.CatalogTTL, or by the catalog API, saving them.
+// Call it before searching concurrently.
+func (c *Client) LoadCatalog(ctx context.Context) error {
+	path := filepath.Join(c.cfg.CacheDir, fmt.Sprintf("catalog-%s-%s.json", c.cfg.Locale, c.catalogCountry()))
+	saved := len(c.cfg.Record) == 0 && len(c.cfg.Replay) == 0
+	if saved {
+		if entries, ok := readCatalog(path, c.cfg.CatalogTTL); ok {
+			c.catalog = newCatalogIndex(entries)
+			return nil
+		}
+	}
+	entries, err := c.fetchCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	c.catalog = newCatalogIndex(entries)
+	if saved {
+		if err = writeCatalog(path, entries); err != nil {
+			slog.Warn("failed to save the catalog", "path", path, "err", err)
+		}
+	}
+	return nil
+}
+
+// readCatalog returns the entries of the saved catalog, if it isn't older than the ttl.
+func readCatalog(path string, ttl time.Duration) ([]CatalogEntry, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var f catalogFile
+	if err = json.Unmarshal(b, &f); err != nil {
+		slog.Warn("failed to read the saved catalog", "path", path, "err", err)
+		return nil, false
+	}
+	if time.Since(f.Fetched) > ttl || len(f.Entries) == 0 {
+		return nil, false
+	}
+	return f.Entries, true
+}
+
+// writeCatalog saves the entries of the catalog, aside and renamed in place like the cache.
+func writeCatalog(path string, entries []CatalogEntry) error {
+	b, err := json.Marshal(catalogFile{Fetched: time.Now(), Entries: entries})
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// catalogCountry is the country of the catalog prices and availability, required by the API.
+func (c *Client) catalogCountry() string {
+	if len(c.cfg.Country) > 0 {
+		return c.cfg.Country
+	}
+	return "US"
+}
+
+// fetchCatalog gets all the pages of the catalog API.
+func (c *Client) fetchCatalog(ctx context.Context) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	for page := 0; page < catalogMaxPages; page++ {
+		vars, err := json.Marshal(map[string]any{"category": catalogCategories, "count": catalogPage,
+			"start": page * catalogPage, "country": c.catalogCountry(), "locale": c.cfg.Locale})
+		if err != nil {
+			return nil, err
+		}
+		link := fmt.Sprintf("%s?operationName=searchStoreQuery&query=%s&variables=%s", catalogAPI,
+			url.QueryEscape(catalogQuery), url.QueryEscape(string(vars)))
+		b, err := c.getBody(ctx, link)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get catalog page %d: %w", page, err)
+		}
+		var resp catalogResponse
+		if err = json.Unmarshal(b, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode catalog page %d: %w", page, err)
+		}
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("catalog page %d: %s", page, resp.Errors[0].Message)
+		}
+		store := resp.Data.Catalog.SearchStore
+		for _, e := range store.Elements {
+			slug := e.ProductSlug
+			if len(e.CatalogNs.Mappings) > 0 {
+				slug = e.CatalogNs.Mappings[0].PageSlug
+			}
+			if slug = strings.TrimSuffix(slug, "/home"); len(slug) == 0 || len(e.Title) == 0 {
+				continue // not on a product page
+			}
+			entry := CatalogEntry{Title: e.Title, Slug: slug, Type: e.OfferType}
+			for _, typ := range catalogImages {
+				if i := slices.IndexFunc(e.KeyImages, func(ki keyImage) bool { return ki.Type == typ }); i >= 0 {
+					entry.Image = e.KeyImages[i].URL
+					break
+				}
+			}
+			entries = append(entries, entry)
+		}
+		if len(store.Elements) < catalogPage || (page+1)*catalogPage >= store.Paging.Total {
+			break
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w in the catalog", ErrNoResults)
+	}
+	return entries, nil
+}
+
+// SearchCatalog searches the titles of the catalog loaded by LoadCatalog for the name without any
+// request, and returns the shortlisted ones like Search. It returns ErrNoResults without a loaded
+// catalog or a similar title, for searching the store instead.
+func (c *Client) SearchCatalog(name string) ([]Match, error) {
+	if c.catalog == nil {
+		return nil, ErrNoResults
+	}
+	entries := c.catalog.shortlist(name)
+	if len(entries) == 0 {
+		return nil, ErrNoResults
+	}
+	c.counters.catalogSearches.Add(1)
+	matches := make([]Match, len(entries))
+	for i, e := range entries {
+		matches[i] = Match{Name: e.Title, Link: c.ProductLink(e.Slug), Image: e.Image, Type: e.Type}
+	}
+	return c.filter(rank(matches, name, c.cfg.Similarity), name), nil
+}
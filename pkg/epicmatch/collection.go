@@ -0,0 +1,145 @@
+package epicmatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxCollection is the maximum number of games of a franchise collection.
+const maxCollection = 10
+
+// collectionSuffixes are the endings of the names of franchise collections after a separator of
+// collectionSeps, folded, without punctuation.
+var collectionSuffixes = []string{
+	"the complete collection", "complete collection", "the collection", "collection", "the trilogy", "trilogy",
+	"the anthology", "anthology", "the saga", "saga",
+}
+
+// collectionSeps separate the franchise from the suffix of a collection name, like "BioShock: The
+// Collection", so the names of single games like "The Banner Saga" aren't taken for collections.
+var collectionSeps = []string{":", " - ", " – ", " — "}
+
+// SplitCollection returns the component titles of a name of several products, like
+// "Borderlands 3" and "Borderlands 3 Season Pass" for "Borderlands 3 + Season Pass": a component
+// of another kind than a game, like a season pass or a soundtrack, is of the first one. A
+// franchise collection, like "BioShock: The Collection", returns the franchise name instead, the
+// suffix being after a colon or a dash. Both are empty for the name of a single product.
+func SplitCollection(name string) (parts []string, franchise string) {
+	raw := strings.TrimSpace(name)
+	name = NormalizeName(name)
+	if split := strings.Split(name, " + "); len(split) > 1 {
+		for _, p := range split {
+			if p = strings.TrimSpace(p); len(p) == 0 {
+				continue
+			}
+			if len(parts) > 0 {
+				if m := (Match{Name: p}); m.Kind() != KindGame {
+					p = parts[0] + " " + p
+				}
+			}
+			parts = append(parts, p)
+		}
+		if len(parts) < 2 {
+			return nil, ""
+		}
+		return parts, ""
+	}
+	cut, sepLen := -1, 0
+	for _, sep := range collectionSeps {
+		if i := strings.LastIndex(raw, sep); i > cut {
+			cut, sepLen = i, len(sep)
+		}
+	}
+	if cut <= 0 || !slices.Contains(collectionSuffixes, plainName.normalize(raw[cut+sepLen:])) {
+		return nil, ""
+	}
+	return nil, NormalizeName(raw[:cut])
+}
+
+// ResolveCollection resolves the components of a collection name by SplitCollection: each
+// component by its product page or an exact search result, the ones without either left without
+// a link, or the games of a franchise by the store search of its name, up to maxCollection. It
+// returns ErrNoResults for the name of a single product, or if none of its components are found.
+func (c *Client) ResolveCollection(ctx context.Context, name string) ([]Match, error) {
+	parts, franchise := SplitCollection(name)
+	if len(franchise) > 0 {
+		return c.franchiseGames(ctx, name, franchise)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: %s is not a collection", ErrNoResults, name)
+	}
+	matches := make([]Match, len(parts))
+	var found bool
+	for i, p := range parts {
+		m, err := c.resolvePart(ctx, p)
+		switch {
+		case err == nil:
+			found = true
+		case errors.Is(err, ErrNoResults):
+			m = Match{Name: p}
+		default:
+			return nil, fmt.Errorf("failed to resolve %s of the collection: %w", p, err)
+		}
+		matches[i] = m
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: none of the components of %s", ErrNoResults, name)
+	}
+	return matches, nil
+}
+
+// resolvePart returns the product page of the name, or the search result of the same name.
+func (c *Client) resolvePart(ctx context.Context, name string) (Match, error) {
+	link, err := c.ResolveExact(ctx, name)
+	if err == nil || errors.Is(err, ErrRegionLocked) && len(link) > 0 {
+		return Match{Name: name, Link: link, Confidence: 100}, nil
+	}
+	if ctx.Err() != nil {
+		return Match{}, ctx.Err()
+	}
+	matches, err := c.Search(ctx, name)
+	if err != nil {
+		return Match{}, err
+	}
+	for _, m := range matches {
+		if SameName(m.Name, name) {
+			m.Confidence = 100
+			return m, nil
+		}
+	}
+	return Match{}, ErrNoResults
+}
+
+// franchiseGames returns the base games in the search results of the franchise, named by the
+// franchise at their start, like "BioShock Infinite" of "BioShock". It needs two of them at least.
+func (c *Client) franchiseGames(ctx context.Context, name, franchise string) ([]Match, error) {
+	matches, err := c.Search(ctx, franchise)
+	if err != nil {
+		return nil, err
+	}
+	prefix := plainName.normalize(franchise)
+	var games []Match
+	bases := map[string]bool{}
+	for _, m := range matches {
+		plain := plainName.normalize(m.Name)
+		if plain != prefix && !strings.HasPrefix(plain, prefix+" ") || SameName(m.Name, name) ||
+			m.Kind() != KindGame || m.IsBundle() || len(m.Store) > 0 {
+			continue
+		}
+		if _, f := SplitCollection(m.Name); len(f) > 0 || bases[m.Base()] {
+			continue
+		}
+		bases[m.Base()] = true
+		m.Confidence = 100
+		if games = append(games, m); len(games) == maxCollection {
+			break
+		}
+	}
+	if len(games) < 2 {
+		return nil, fmt.Errorf("%w: no games of the franchise %s", ErrNoResults, franchise)
+	}
+	return games, nil
+}
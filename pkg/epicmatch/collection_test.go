@@ -0,0 +1,35 @@
+package epicmatch
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestSplitCollection splits the names of several products and franchise collections, and keeps
+// the names of single games that only end like collections.
+func TestSplitCollection(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		parts     []string
+		franchise string
+	}{
+		{"Borderlands 3 + Season Pass", []string{"Borderlands 3", "Borderlands 3 Season Pass"}, ""},
+		{"Hades + Celeste", []string{"Hades", "Celeste"}, ""},
+		{"BioShock: The Collection", nil, "BioShock"},
+		{"Tomb Raider - Trilogy", nil, "Tomb Raider"},
+		{"Metro: The Saga", nil, "Metro"},
+		{"Borderlands: The Handsome Collection", nil, ""},
+		{"The Banner Saga", nil, ""},
+		{"The Banner Saga 3", nil, ""},
+		{"Mass Effect Trilogy", nil, ""},
+		{"The Witcher 3: Wild Hunt Saga", nil, ""},
+		{"Assassin's Creed Anthology", nil, ""},
+		{"Disney+ Game", nil, ""},
+		{": Collection", nil, ""},
+	} {
+		parts, franchise := SplitCollection(tc.name)
+		if !slices.Equal(parts, tc.parts) || franchise != tc.franchise {
+			t.Errorf("SplitCollection(%q) = %q, %q, want %q, %q", tc.name, parts, franchise, tc.parts, tc.franchise)
+		}
+	}
+}
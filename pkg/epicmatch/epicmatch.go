@@ -0,0 +1,252 @@
+// Package epicmatch finds the Epic Games Store product pages of games by name. It guesses the
+// product page from the name, searches the store ranking the results by similarity, and can search
+// by the logo of the game or in other stores as a fallback. The store specific parts are behind
+// the Store interface, so other storefronts can use the same matching.
+package epicmatch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// Host is the Epic Games Store address.
+	Host = "https://store.epicgames.com"
+	// DefaultLocale is the store locale of the links without Config.Locale.
+	DefaultLocale = "en-US"
+)
+
+var (
+	// ErrNoResults is returned when the store search has no results at all.
+	ErrNoResults = errors.New("no search results")
+	// ErrTooManyRetries is returned when the store answers with Cloudflare challenges only.
+	ErrTooManyRetries = errors.New("too many retries")
+	// ErrTimeout is returned for store requests running longer than Config.Timeout.
+	ErrTimeout = errors.New("request timed out")
+	// ErrAgeGated is returned for the age gate of a mature game served instead of its product page,
+	// even with the age verification cookie.
+	ErrAgeGated = errors.New("age gated page")
+	// ErrRegionLocked is returned with the link of a product page that isn't available in the
+	// region of the store requests, unlike a missing page.
+	ErrRegionLocked = errors.New("unavailable in your region")
+	// ErrParse is returned for search results that couldn't be parsed, usually after a store
+	// layout change.
+	ErrParse = errors.New("failed to parse")
+	// ErrUnavailable is returned when the fetcher of the store pages can't run at all, like a
+	// missing curl or a headless browser failing to start.
+	ErrUnavailable = errors.New("fetch backend unavailable")
+)
+
+// IsTransient returns true for the errors that may pass on a retry later, like Cloudflare
+// challenges, dead proxies and timeouts.
+func IsTransient(err error) bool {
+	if errors.Is(err, ErrTooManyRetries) || errors.Is(err, ErrNoProxy) || errors.Is(err, ErrTimeout) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// Match is a search result for a game name.
+type Match struct {
+	Name string
+	Link string
+	// Rank is the Levenshtein distance from the searched name, or 100 - token set ratio with
+	// Similarity.TokenSet. It's 0 for substrings, lower is better.
+	Rank int
+	// Confidence is the normalized rank: 0-100, 100 for the same name.
+	Confidence int
+	// Store is the name of the fallback store for its results, empty for Epic.
+	Store string
+	// Image is the thumbnail of the result in the store, if any.
+	Image string
+	// Type is the type of the result in the store, like "Base Game" or "Add-On", see Kind.
+	Type string
+}
+
+// Config configures a Client.
+type Config struct {
+	// Delay is the minimum delay between store requests, of the search pages and of the other
+	// pages independently. It grows automatically on Cloudflare challenges, and recovers on
+	// successful requests.
+	Delay time.Duration
+	// ImageDelay is the minimum delay between image search requests, independent of the store.
+	ImageDelay time.Duration
+	// BreakAfter is the number of Cloudflare challenges in a row pausing all store requests for
+	// Cooldown, instead of burning the retries of every game, 0 never pauses. See PausedUntil.
+	BreakAfter int
+	Cooldown   time.Duration
+	// Timeout is the maximum time of a request, 0 doesn't limit it.
+	Timeout time.Duration
+	// PageSize is the number of search results on a page.
+	PageSize int
+	// MaxResults is the maximum number of search results to rank, got by pages of PageSize. It's
+	// a single page by default.
+	MaxResults int
+	// CacheDir is the directory of cached responses, CacheTTL is their maximum age. Caching is
+	// disabled for zero CacheTTL.
+	CacheDir string
+	CacheTTL time.Duration
+	// Headers override or extend the browser headers of store requests. Names are case
+	// insensitive.
+	Headers map[string]string
+	// Locale is the store locale of the links and the accept-language header, like de-DE.
+	Locale string
+	// Country is the ISO 3166 alpha-2 country code of the store region for search results and
+	// prices, like DE. The store guesses it from the IP address when empty.
+	Country string
+	// Clearance is a cf_clearance cookie value copied from the browser, so store requests skip
+	// the Cloudflare challenge. Use it with the user agent header of the same browser.
+	Clearance string
+	// Solver is the FlareSolverr endpoint, like http://localhost:8191/v1. Challenged store
+	// requests are routed through it instead of retrying.
+	Solver string
+	// Record is a directory to save all responses to, Replay is a directory to serve the saved
+	// responses from instead of the network. Both disable the cache.
+	Record, Replay string
+	// Fetcher replaces the network access, if set.
+	Fetcher Fetcher
+	// Browser gets the store pages by a headless Chrome or Chromium running their JavaScript
+	// instead of curl, so Cloudflare passes naturally. BrowserPath is its executable, found on the
+	// system by default. Close the client to stop it.
+	Browser     bool
+	BrowserPath string
+	// ImageSearch is the name of the image search backend, lens by default, see ImageSearches.
+	// ImageSearchKey is the API key of serpapi, bing or tineye.
+	ImageSearch, ImageSearchKey string
+	// Similarity is the pipeline of ranking the search results, the bare Levenshtein distance by
+	// default.
+	Similarity Similarity
+	// Exclude are the kinds of search results to drop, like KindDLC, see ParseKinds.
+	Exclude []string
+	// Types are the product categories searched, like TypeGames, all of them without any, see
+	// ParseTypes.
+	Types []string
+	// Proxies are the proxy URLs rotated per store request, see CheckProxy. Failed ones are
+	// skipped for a while.
+	Proxies []string
+	// Locales are the other store locales searched when there's no result of the same name, for
+	// localized names, like ja or de-DE. Their links are in Locale.
+	Locales []string
+	// RegionLocale is the store locale of trying again the product pages unavailable in the
+	// region, like en-US, their link is in this locale if it's available there.
+	RegionLocale string
+	// Store is the name of the store searched, epic by default, see SetStore for others.
+	Store string
+	// CatalogTTL is the maximum age of the titles of the store catalog saved in CacheDir by
+	// LoadCatalog, a week by default.
+	CatalogTTL time.Duration
+}
+
+// Client searches the store with its own rate limiting. It's safe for concurrent use.
+type Client struct {
+	cfg Config
+	// rates are the limiters of the requests by domain, see rateOf.
+	rates map[string]*limiter
+	http  *http.Client
+	// notFound is on the not found page of the locale.
+	notFound []byte
+	cf       clearance
+	fetcher  Fetcher
+	img      ImageSearch
+	store    Store
+	proxies  *proxies
+	counters counters
+	// browser is the fetcher of Config.Browser, nil without it.
+	browser *browserFetcher
+	// catalog is the title index of LoadCatalog, nil without it.
+	catalog *catalogIndex
+}
+
+// New returns a client with the given configuration.
+func New(cfg Config) *Client {
+	if cfg.PageSize < 1 {
+		cfg.PageSize = 40
+	}
+	if cfg.MaxResults < cfg.PageSize {
+		cfg.MaxResults = cfg.PageSize
+	}
+	if len(cfg.CacheDir) == 0 {
+		cfg.CacheDir = DefaultCacheDir()
+	}
+	if len(cfg.Locale) == 0 {
+		cfg.Locale = DefaultLocale
+	}
+	if cfg.CatalogTTL <= 0 {
+		cfg.CatalogTTL = 7 * 24 * time.Hour
+	}
+	cfg.Country = strings.ToUpper(cfg.Country)
+	if len(cfg.Record) > 0 || len(cfg.Replay) > 0 {
+		cfg.CacheTTL = 0
+	}
+	c := &Client{cfg: cfg, rates: map[string]*limiter{
+		rateBrowse:  newLimiter(cfg.Delay, cfg.BreakAfter, cfg.Cooldown),
+		rateProduct: newLimiter(cfg.Delay, cfg.BreakAfter, cfg.Cooldown),
+		rateImage:   newLimiter(cfg.ImageDelay, 0, 0),
+	}, http: &http.Client{Timeout: cfg.Timeout}, notFound: []byte("/" + cfg.Locale + "/not-found"), cf: clearance{cookie: cfg.Clearance},
+		proxies: newProxies(cfg.Proxies, cfg.Timeout)}
+	switch {
+	case cfg.Fetcher != nil:
+		c.fetcher = cfg.Fetcher
+	case len(cfg.Replay) > 0:
+		c.fetcher = Replay(cfg.Replay)
+	case cfg.Browser:
+		c.browser = newBrowserFetcher(c)
+		c.fetcher = c.browser
+	default:
+		c.fetcher = network{c}
+	}
+	if len(cfg.Record) > 0 {
+		c.fetcher = Record(cfg.Record, c.fetcher)
+	}
+	newImg, ok := imageSearches[cfg.ImageSearch]
+	if !ok {
+		newImg = imageSearches["lens"]
+	}
+	c.img = newImg(c)
+	newStore, ok := stores[cfg.Store]
+	if !ok {
+		newStore = stores["epic"]
+	}
+	c.store = newStore(c)
+	return c
+}
+
+// SetDelay changes the minimum delay between store requests, eg. for a slower retry.
+func (c *Client) SetDelay(d time.Duration) {
+	c.rates[rateBrowse].setBase(d)
+	c.rates[rateProduct].setBase(d)
+}
+
+// PausedUntil returns the end of the pause of the store requests after Config.BreakAfter
+// challenges in a row, in the past when they aren't paused.
+func (c *Client) PausedUntil() time.Time {
+	until := c.rates[rateBrowse].pausedUntil()
+	if p := c.rates[rateProduct].pausedUntil(); p.After(until) {
+		until = p
+	}
+	return until
+}
+
+// Default is the client of the package level functions.
+var Default = New(Config{Delay: time.Millisecond * 300})
+
+// Search searches the store by Default for the given name. See Client.Search.
+func Search(ctx context.Context, name string) ([]Match, error) {
+	return Default.Search(ctx, name)
+}
+
+// ResolveExact returns the product page of the given name by Default. See Client.ResolveExact.
+func ResolveExact(ctx context.Context, name string) (string, error) {
+	return Default.ResolveExact(ctx, name)
+}
+
+// ResolveVariant returns the product page of the given name by Default, with the variant of the
+// name it's found by. See Client.ResolveVariant.
+func ResolveVariant(ctx context.Context, name string) (link, variant string, err error) {
+	return Default.ResolveVariant(ctx, name)
+}
@@ -0,0 +1,102 @@
+package epicmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// fallbackStore searches a storefront other than Epic by game name.
+type fallbackStore struct {
+	name   string // shown on the badge of the card
+	search func(c *Client, ctx context.Context, name string) ([]Match, error)
+}
+
+var fallbackStores = map[string]fallbackStore{
+	"steam": {name: "Steam", search: (*Client).steamSearch},
+	"gog":   {name: "GOG", search: (*Client).gogSearch},
+}
+
+// FallbackStore returns the display name of the fallback store by its key, eg. "steam".
+func FallbackStore(key string) (string, error) {
+	fs, ok := fallbackStores[key]
+	if !ok {
+		return "", fmt.Errorf("unknown fallback store %q, use steam or gog", key)
+	}
+	return fs.name, nil
+}
+
+// SearchFallback searches the fallback store by its key, eg. "steam". The matches are ranked like
+// Search does, with their Store set.
+func (c *Client) SearchFallback(ctx context.Context, key, name string) ([]Match, error) {
+	fs, ok := fallbackStores[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown fallback store %q, use steam or gog", key)
+	}
+	matches, err := fs.search(c, ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		matches[i].Store = fs.name
+	}
+	return c.filter(rank(matches, name, c.cfg.Similarity), name), nil
+}
+
+// steamSearch uses the store search API of Steam.
+func (c *Client) steamSearch(ctx context.Context, name string) ([]Match, error) {
+	link := "https://store.steampowered.com/api/storesearch/?l=english&cc=US&term=" + url.QueryEscape(name)
+	var res struct {
+		Items []struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			Image string `json:"tiny_image"`
+		} `json:"items"`
+	}
+	if err := c.getJSON(ctx, link, &res); err != nil {
+		return nil, err
+	}
+	matches := make([]Match, 0, len(res.Items))
+	for _, it := range res.Items {
+		matches = append(matches, Match{Name: it.Name, Link: fmt.Sprintf("https://store.steampowered.com/app/%d/", it.ID),
+			Image: it.Image})
+	}
+	return matches, nil
+}
+
+// gogSearch uses the catalog filtering API of GOG.
+func (c *Client) gogSearch(ctx context.Context, name string) ([]Match, error) {
+	link := "https://embed.gog.com/games/ajax/filtered?mediaType=game&search=" + url.QueryEscape(name)
+	var res struct {
+		Products []struct {
+			Title string `json:"title"`
+			URL   string `json:"url"`
+			Image string `json:"image"` // without scheme and size suffix
+		} `json:"products"`
+	}
+	if err := c.getJSON(ctx, link, &res); err != nil {
+		return nil, err
+	}
+	matches := make([]Match, 0, len(res.Products))
+	for _, p := range res.Products {
+		m := Match{Name: p.Title, Link: "https://www.gog.com" + p.URL}
+		if len(p.Image) > 0 {
+			m.Image = "https:" + p.Image + "_196.jpg"
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// getJSON does an HTTP GET request and decodes the JSON response body into v.
+func (c *Client) getJSON(ctx context.Context, link string, v any) error {
+	b, err := c.getBody(ctx, link)
+	if err != nil {
+		return fmt.Errorf("failed to read response of %s: %w", link, err)
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", link, err)
+	}
+	return nil
+}
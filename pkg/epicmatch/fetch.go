@@ -0,0 +1,396 @@
+package epicmatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	retries = 3
+	// killWait is how long the output of a killed fetch command is waited for.
+	killWait = time.Second
+)
+
+var retryB = []byte("<title>Just a moment...</title>")
+
+// ageGateCookie tells the store the age of the visitor was verified, like the birth date entered in
+// the browser, so the product pages of mature games are served instead of the age gate.
+var ageGateCookie = [2]string{"HAS_ACCEPTED_AGE_GATE_ONCE", "true"}
+
+// ageGateB are the marks of the age gate interstitial, one of them is enough.
+var ageGateB = [][]byte{[]byte(`data-testid="age-gate"`), []byte(`id="age-gate"`)}
+
+// ageGated tells if the page is the age gate instead of the product page.
+func ageGated(b []byte) bool {
+	return slices.ContainsFunc(ageGateB, func(mark []byte) bool { return bytes.Contains(b, mark) })
+}
+
+// regionLockB are the marks of the product page of a game unavailable in the region, one of them
+// is enough. The page also links the not found page, so it's checked first.
+var regionLockB = [][]byte{[]byte(`data-testid="region-restricted"`), []byte(`"isBlockedInRegion":true`),
+	[]byte(`"regionRestricted":true`)}
+
+// regionLocked tells if the product page is unavailable in the region of the request.
+func regionLocked(b []byte) bool {
+	return slices.ContainsFunc(regionLockB, func(mark []byte) bool { return bytes.Contains(b, mark) })
+}
+
+// dumpName replaces the characters of a link that are invalid in file names on any OS.
+var dumpName = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", `"`, "-", "<", "-", ">", "-", "|", "-")
+
+// browser is the identity of a Chrome running on an OS, sent in the request headers.
+type browser struct {
+	platform, version, agent string
+}
+
+var browsers = map[string]browser{
+	"linux": {`"Linux"`, `"6.12.0"`,
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/128.0.0.0 Safari/537.36"},
+	"darwin": {`"macOS"`, `"14.6.1"`,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/128.0.0.0 Safari/537.36"},
+	"windows": {`"Windows"`, `"15.0.0"`,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/128.0.0.0 Safari/537.36"},
+}
+
+// currentBrowser returns the browser of the current OS, or the Linux one for other systems.
+func currentBrowser() browser {
+	if b, ok := browsers[runtime.GOOS]; ok {
+		return b
+	}
+	return browsers["linux"]
+}
+
+// pageHeaders returns the headers of a page navigation in the order a browser sends them, with
+// the configured ones replacing or following them.
+func (c *Client) pageHeaders() [][2]string {
+	b := currentBrowser()
+	hs := [][2]string{
+		{"accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+		{"accept-language", c.acceptLanguage()},
+		{"cache-control", "no-cache"},
+		{"dnt", "1"},
+		{"pragma", "no-cache"},
+		{"priority", "u=0, i"},
+		{"sec-ch-ua", `"Not;A=Brand";v="24", "Chromium";v="128"`},
+		{"sec-ch-ua-mobile", "?0"},
+		{"sec-ch-ua-platform", b.platform},
+		{"sec-fetch-dest", "document"},
+		{"sec-fetch-mode", "navigate"},
+		{"sec-fetch-site", "none"},
+		{"sec-fetch-user", "?1"},
+		{"upgrade-insecure-requests", "1"},
+		{"user-agent", b.agent},
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.cfg.Headers)) {
+		i := slices.IndexFunc(hs, func(h [2]string) bool { return strings.EqualFold(h[0], name) })
+		if i < 0 {
+			hs = append(hs, [2]string{name, c.cfg.Headers[name]})
+		} else {
+			hs[i][1] = c.cfg.Headers[name]
+		}
+	}
+	cookies := ageGateCookie[0] + "=" + ageGateCookie[1]
+	cookie, agent := c.cf.get()
+	if len(cookie) > 0 {
+		cookies += "; cf_clearance=" + cookie
+	}
+	hs = append(hs, [2]string{"cookie", cookies})
+	if len(agent) > 0 {
+		i := slices.IndexFunc(hs, func(h [2]string) bool { return strings.EqualFold(h[0], "user-agent") })
+		hs[i][1] = agent
+	}
+	return hs
+}
+
+// acceptLanguage returns the accept-language header value of the locale, falling back to its
+// language.
+func (c *Client) acceptLanguage() string {
+	lang, _, ok := strings.Cut(c.cfg.Locale, "-")
+	if !ok {
+		return lang
+	}
+	return c.cfg.Locale + "," + lang + ";q=0.9"
+}
+
+// countryQuery returns the country query parameter of the store region after sep, if any.
+func (c *Client) countryQuery(sep string) string {
+	if len(c.cfg.Country) == 0 {
+		return ""
+	}
+	return sep + "country=" + url.QueryEscape(c.cfg.Country)
+}
+
+// curlCmd returns the command getting the link with the given curl binary and headers, through
+// the proxy if any.
+func curlCmd(ctx context.Context, curl, link, proxy string, headers [][2]string) *exec.Cmd {
+	args := []string{link}
+	if len(proxy) > 0 {
+		args = append(args, "--proxy", proxy)
+	}
+	for _, h := range headers {
+		args = append(args, "-H", h[0]+": "+h[1])
+	}
+	return exec.CommandContext(ctx, curl, args...)
+}
+
+// Fetcher gets the responses from the network, or from somewhere else for testing, like Replay.
+type Fetcher interface {
+	// Fetch returns the response body of the link. store is true for the Epic store pages, which
+	// need to pass the Cloudflare protection.
+	Fetch(ctx context.Context, link string, store bool) (io.ReadCloser, error)
+}
+
+// network is the Fetcher of the client getting the responses from the internet.
+type network struct {
+	c *Client
+}
+
+func (n network) Fetch(ctx context.Context, link string, store bool) (io.ReadCloser, error) {
+	if !store {
+		return n.c.plainGet(ctx, link)
+	}
+	buf, err := n.c.storeGet(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return pooledReader{buf}, nil
+}
+
+// pooledReader puts the buffer back to the pool on Close.
+type pooledReader struct {
+	*bytes.Buffer
+}
+
+func (r pooledReader) Close() error {
+	putBuf(r.Buffer)
+	return nil
+}
+
+// epicGet gets the Epic store page by the fetcher, through the cache. The returned buffer should
+// be put back to the pool.
+func (c *Client) epicGet(ctx context.Context, link string) (*bytes.Buffer, error) {
+	if buf, ok := c.cacheGet(link); ok {
+		if !ageGated(buf.Bytes()) {
+			c.counters.cacheHits.Add(1)
+			progressed(ctx)
+			return buf, nil
+		}
+		putBuf(buf) // cached before passing the age gate
+	}
+	start := time.Now()
+	body, err := c.fetcher.Fetch(ctx, link, true)
+	c.counters.request(start)
+	progressed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	buf := getBuf(pageBuf)
+	if _, err = buf.ReadFrom(body); err != nil {
+		putBuf(buf)
+		return nil, fmt.Errorf("failed to read %s: %w", link, err)
+	}
+	if ageGated(buf.Bytes()) {
+		putBuf(buf)
+		return nil, fmt.Errorf("%w: %s", ErrAgeGated, link)
+	}
+	c.cachePut(link, buf.Bytes())
+	return buf, nil
+}
+
+// storeGet is a hack for HTTP GET from epicgames.com executing command line curl (or PowerShell on
+// Windows without curl), because go's HTTP response status is always 403 Forbidden even with the
+// headers copied from the browser.
+// It does a retry on failure, backing off with the rate limiter, or gets the page through the
+// solver if configured. Requests rotate the proxies if any, failed ones are skipped for a while.
+// The returned buffer should be put back to the pool.
+func (c *Client) storeGet(ctx context.Context, link string) (stdout *bytes.Buffer, err error) {
+	rate := c.storeRate(link)
+	for i := 0; i < retries; i++ {
+		if err = rate.wait(ctx); err != nil {
+			return nil, err
+		}
+		var p *proxyServer
+		if p, err = c.proxies.get(); err != nil {
+			return nil, err
+		}
+		var proxy string
+		if p != nil {
+			proxy = p.url
+		}
+		rctx, cancel := ctx, context.CancelFunc(func() {})
+		if c.cfg.Timeout > 0 {
+			rctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		}
+		cmd := fetchCmd(rctx, link, proxy, c.pageHeaders())
+		cmd.WaitDelay = killWait // for the children of a killed command keeping its output open
+		stdout = getBuf(pageBuf)
+		cmd.Stdout = stdout
+		err = cmd.Run()
+		if rctx.Err() != nil && ctx.Err() == nil {
+			// killed the stuck command
+			err = fmt.Errorf("%w after %s", ErrTimeout, c.cfg.Timeout)
+		}
+		cancel()
+		if errors.Is(err, exec.ErrNotFound) {
+			putBuf(stdout)
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		if err != nil {
+			putBuf(stdout)
+			if p == nil || ctx.Err() != nil {
+				return nil, err
+			}
+			// retry through the next proxy
+			c.proxies.dead(p, err)
+			if i == retries-1 {
+				return nil, fmt.Errorf("failed to get %s through proxy %s: %w", link, proxy, err)
+			}
+			continue
+		}
+		b := stdout.Bytes()
+		if bytes.Contains(b, c.notFound) || !bytes.Contains(b, retryB) {
+			rate.passed()
+			return stdout, nil
+		}
+		c.challenged(rate)
+		progressed(ctx)
+		if len(c.cfg.Solver) > 0 {
+			putBuf(stdout)
+			if stdout, err = c.solve(ctx, link); err != nil {
+				return nil, err
+			}
+			if bytes.Contains(stdout.Bytes(), retryB) {
+				break
+			}
+			rate.solved()
+			return stdout, nil
+		}
+		if i < retries-1 {
+			putBuf(stdout)
+		}
+	}
+	defer putBuf(stdout)
+	dump := filepath.Join(os.TempDir(), "epic"+dumpName.Replace(link)+".html")
+	if err = os.WriteFile(dump, stdout.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w for %s", ErrTooManyRetries, link)
+}
+
+// httpGet gets the link by the fetcher, and returns the body io.Reader on success. The body is
+// served from the cache if possible, or cached when read to the end.
+func (c *Client) httpGet(ctx context.Context, link string) (io.ReadCloser, error) {
+	if b, ok := c.cacheGet(link); ok {
+		c.counters.cacheHits.Add(1)
+		progressed(ctx)
+		return pooledReader{b}, nil
+	}
+	start := time.Now()
+	body, err := c.fetcher.Fetch(ctx, link, false)
+	c.counters.request(start)
+	progressed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.newCacheReader(link, body), nil
+}
+
+// plainGet does an HTTP GET request to the given url with the browser headers, through the next
+// proxy if any, and returns the body io.Reader on success.
+func (c *Client) plainGet(ctx context.Context, link string) (io.ReadCloser, error) {
+	if rate := c.rateOf(link); rate != nil {
+		if err := rate.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to http.Do NewRequest %s: %w", link, err)
+	}
+	b := currentBrowser()
+	req.Header.Set("accept",
+		"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7")
+	req.Header.Set("accept-language", c.acceptLanguage())
+	req.Header.Set("cache-control", "no-cache")
+	req.Header.Set("dnt", "1")
+	req.Header.Set("pragma", "no-cache")
+	req.Header.Set("priority", "u=0, i")
+	req.Header.Set("sec-ch-ua", `"Not;A=Brand";v="24", "Chromium";v="128"`)
+	req.Header.Set("sec-ch-ua-arch", `"x86"`)
+	req.Header.Set("sec-ch-ua-bitness", `"64"`)
+	req.Header.Set("sec-ch-ua-form-factors", `"Desktop"`)
+	req.Header.Set("sec-ch-ua-full-version", `"128.0.6613.119"`)
+	req.Header.Set("sec-ch-ua-full-version-list", `"Not;A=Brand";v="24.0.0.0", "Chromium";v="128.0.6613.119"`)
+	req.Header.Set("sec-ch-ua-mobile", "?0")
+	req.Header.Set("sec-ch-ua-model", `""`)
+	req.Header.Set("sec-ch-ua-platform", b.platform)
+	req.Header.Set("sec-ch-ua-platform-version", b.version)
+	req.Header.Set("sec-ch-ua-wow64", "?0")
+	req.Header.Set("sec-fetch-dest", "document")
+	req.Header.Set("sec-fetch-mode", "navigate")
+	req.Header.Set("sec-fetch-site", "none")
+	req.Header.Set("sec-fetch-user", "?1")
+	req.Header.Set("upgrade-insecure-requests", "1")
+	req.Header.Set("user-agent", b.agent)
+	for name, value := range c.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	var resp *http.Response
+	for i := 0; ; i++ {
+		client := c.http
+		var p *proxyServer
+		if p, err = c.proxies.get(); err != nil {
+			return nil, err
+		}
+		if p != nil {
+			client = p.http
+		}
+		if resp, err = client.Do(req); err != nil {
+			if p != nil && ctx.Err() == nil {
+				c.proxies.dead(p, err)
+				if i < retries-1 {
+					continue // through the next proxy
+				}
+			}
+			return nil, fmt.Errorf("failed to http.Do GET %s: %w", link, err)
+		}
+		wait := retryAfter(resp.Header.Get("retry-after"))
+		tooMany := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if !tooMany || wait == 0 || i == retries-1 {
+			break
+		}
+		resp.Body.Close()
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("wrong status for getting %s: %s", link, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Get does an HTTP GET request with the browser headers, through the cache. It's useful for
+// downloading logos and thumbnails. The returned body should be closed.
+func (c *Client) Get(ctx context.Context, link string) (io.ReadCloser, error) {
+	return c.httpGet(ctx, link)
+}
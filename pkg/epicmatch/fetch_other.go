@@ -0,0 +1,14 @@
+//go:build !windows
+
+package epicmatch
+
+import (
+	"context"
+	"os/exec"
+)
+
+// fetchCmd returns the command writing the page body of the link to stdout, through the proxy if
+// any.
+func fetchCmd(ctx context.Context, link, proxy string, headers [][2]string) *exec.Cmd {
+	return curlCmd(ctx, "curl", link, proxy, headers)
+}
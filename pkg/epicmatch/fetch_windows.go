@@ -0,0 +1,49 @@
+package epicmatch
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// psScript gets the page with PowerShell, writing the body as UTF-8 without the progress bar.
+// Error pages are written too, like curl does.
+const psScript = `$ProgressPreference = 'SilentlyContinue'
+[Console]::OutputEncoding = [Text.Encoding]::UTF8
+try { $r = (Invoke-WebRequest -UseBasicParsing -Uri %s -UserAgent %s -Headers @{%s}%s).Content }
+catch {
+	if (-not $_.Exception.Response) { exit 1 }
+	$r = (New-Object IO.StreamReader($_.Exception.Response.GetResponseStream())).ReadToEnd()
+}
+[Console]::Out.Write($r)`
+
+// fetchCmd returns the command writing the page body of the link to stdout, through the proxy if
+// any. curl.exe ships with Windows 10 and later, PowerShell is used without it, which supports
+// only HTTP proxies.
+func fetchCmd(ctx context.Context, link, proxy string, headers [][2]string) *exec.Cmd {
+	if curl, err := exec.LookPath("curl.exe"); err == nil {
+		return curlCmd(ctx, curl, link, proxy, headers)
+	}
+	var agent string
+	pairs := make([]string, 0, len(headers))
+	for _, h := range headers {
+		// Windows PowerShell refuses the user agent among the headers
+		if strings.EqualFold(h[0], "user-agent") {
+			agent = h[1]
+			continue
+		}
+		pairs = append(pairs, psQuote(h[0])+"="+psQuote(h[1]))
+	}
+	var proxyArg string
+	if len(proxy) > 0 {
+		proxyArg = " -Proxy " + psQuote(proxy)
+	}
+	script := fmt.Sprintf(psScript, psQuote(link), psQuote(agent), strings.Join(pairs, ";"), proxyArg)
+	return exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+}
+
+// psQuote returns s as a single quoted PowerShell string.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
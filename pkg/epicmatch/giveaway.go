@@ -0,0 +1,125 @@
+package epicmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// promotionsURL lists the current and upcoming promotions of the store, including giveaways.
+const promotionsURL = "https://store-site-backend-static.ak.epicgames.com/freeGamesPromotions?locale=%s&country=%s&allowCountries=%s"
+
+// Giveaway is a period when a game was free on the store.
+type Giveaway struct {
+	Title string    `json:"title"`
+	Slug  string    `json:"slug"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// promotions is the used part of the store promotions response.
+type promotions struct {
+	Data struct {
+		Catalog struct {
+			SearchStore struct {
+				Elements []struct {
+					Title       string `json:"title"`
+					ProductSlug string `json:"productSlug"`
+					CatalogNs   struct {
+						Mappings []struct {
+							PageSlug string `json:"pageSlug"`
+						} `json:"mappings"`
+					} `json:"catalogNs"`
+					Promotions *struct {
+						Current  []offers `json:"promotionalOffers"`
+						Upcoming []offers `json:"upcomingPromotionalOffers"`
+					} `json:"promotions"`
+				} `json:"elements"`
+			} `json:"searchStore"`
+		} `json:"Catalog"`
+	} `json:"data"`
+}
+
+type offers struct {
+	Offers []struct {
+		Start    time.Time `json:"startDate"`
+		End      time.Time `json:"endDate"`
+		Discount struct {
+			Percentage int `json:"discountPercentage"`
+		} `json:"discountSetting"`
+	} `json:"promotionalOffers"`
+}
+
+// Giveaways returns the free game periods of the store. An empty source gets the current and
+// upcoming ones from the store, others are a file path or a link of a JSON list of Giveaway, or
+// of a saved store promotions response, for the history.
+func (c *Client) Giveaways(ctx context.Context, source string) ([]Giveaway, error) {
+	var b []byte
+	var err error
+	switch {
+	case len(source) == 0:
+		country := c.cfg.Country
+		if len(country) == 0 {
+			country = "US"
+		}
+		b, err = c.getBody(ctx, fmt.Sprintf(promotionsURL, c.cfg.Locale, country, country))
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		b, err = c.getBody(ctx, source)
+	default:
+		b, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get giveaways: %w", err)
+	}
+	var list []Giveaway
+	if err = json.Unmarshal(b, &list); err == nil {
+		return list, nil
+	}
+	var p promotions
+	if err = json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode giveaways: %w", err)
+	}
+	for _, e := range p.Data.Catalog.SearchStore.Elements {
+		if e.Promotions == nil {
+			continue
+		}
+		slug := e.ProductSlug
+		if len(e.CatalogNs.Mappings) > 0 {
+			slug = e.CatalogNs.Mappings[0].PageSlug
+		}
+		for _, ps := range append(e.Promotions.Current, e.Promotions.Upcoming...) {
+			for _, o := range ps.Offers {
+				if o.Discount.Percentage == 0 {
+					list = append(list, Giveaway{Title: e.Title, Slug: strings.TrimSuffix(slug, "/home"),
+						Start: o.Start, End: o.End})
+				}
+			}
+		}
+	}
+	return list, nil
+}
+
+// getBody returns the whole body of the link, through the cache. Reading to the end lets the
+// response to be cached.
+func (c *Client) getBody(ctx context.Context, link string) ([]byte, error) {
+	body, err := c.httpGet(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// Slug returns the product slug of a store product page link, empty for other links.
+func Slug(link string) string {
+	if !IsProduct(link) {
+		return ""
+	}
+	_, slug, _ := strings.Cut(link, "/p/")
+	slug, _, _ = strings.Cut(slug, "?")
+	return strings.TrimSuffix(slug, "/")
+}
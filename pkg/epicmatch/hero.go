@@ -0,0 +1,50 @@
+package epicmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	reKeyImage = regexp.MustCompile(`\{[^{}]*"type":"(?:DieselGameBoxWide|OfferImageWide|DieselStoreFrontWide)"[^{}]*\}`)
+	reOGImage  = regexp.MustCompile(`<meta[^>]+property="og:image"[^>]+content="([^"]+)"`)
+)
+
+// heroTypes are the key image types of the landscape artwork of a product, in order.
+var heroTypes = []string{"DieselGameBoxWide", "OfferImageWide", "DieselStoreFrontWide"}
+
+// HeroImage scrapes the landscape artwork of the game from the key images in the embedded state of
+// the store product page, or from its preview image for the social sites without them.
+func (c *Client) HeroImage(ctx context.Context, link string) (string, error) {
+	buf, err := c.epicGet(ctx, c.productPage(link))
+	if err != nil {
+		return "", fmt.Errorf("failed to get product page %s for hero image: %w", link, err)
+	}
+	defer putBuf(buf)
+	b := buf.Bytes()
+	best, hero := len(heroTypes), ""
+	for _, obj := range reKeyImage.FindAll(b, -1) {
+		var img struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		}
+		if json.Unmarshal(obj, &img) != nil || len(img.URL) == 0 {
+			continue
+		}
+		if i := slices.Index(heroTypes, img.Type); i >= 0 && i < best {
+			best, hero = i, img.URL
+		}
+	}
+	if len(hero) > 0 {
+		return hero, nil
+	}
+	if m := reOGImage.FindSubmatch(b); m != nil {
+		return html.UnescapeString(string(m[1])), nil
+	}
+	return "", fmt.Errorf("no hero image found on %s", link)
+}
@@ -0,0 +1,78 @@
+package epicmatch
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+
+	_ "golang.org/x/image/webp"
+)
+
+// ImageHash returns the difference hash of the image of the URL: 64 bits telling if each pixel
+// of the picture shrunk to 9x8 is brighter than its right neighbor. Similar pictures have close
+// hashes regardless of their size and compression, see HashDistance.
+func (c *Client) ImageHash(ctx context.Context, link string) (uint64, error) {
+	body, err := c.Get(ctx, link)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	img, _, err := image.Decode(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image %s: %w", link, err)
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return 0, fmt.Errorf("empty image %s", link)
+	}
+	return dHash(img), nil
+}
+
+// dHash computes the difference hash of the image, averaging the pixels of each cell.
+func dHash(img image.Image) uint64 {
+	const w, h = 9, 8
+	b := img.Bounds()
+	var gray [h][w]float64
+	for y := range h {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+		for x := range w {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+			var sum float64
+			n := 0
+			for py := y0; py < max(y1, y0+1); py++ {
+				for px := x0; px < max(x1, x0+1); px++ {
+					sum += luma(img.At(px, py))
+					n++
+				}
+			}
+			gray[y][x] = sum / float64(n)
+		}
+	}
+	var hash uint64
+	for y := range h {
+		for x := range w - 1 {
+			hash <<= 1
+			if gray[y][x] > gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// luma returns the brightness of the color over a white background, for transparent logos.
+func luma(c color.Color) float64 {
+	r, g, b, a := c.RGBA()
+	white := float64(0xffff - a)
+	return 0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(b)+white)
+}
+
+// HashDistance returns the number of different bits of the image hashes, 0 for the same picture.
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
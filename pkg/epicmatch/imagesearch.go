@@ -0,0 +1,225 @@
+package epicmatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// imageResults is the maximum number of distinct links of an image search.
+const imageResults = 3
+
+// ImageSearch finds the web pages showing an image.
+type ImageSearch interface {
+	// Pages returns the links of the pages showing the image of the URL, best first.
+	Pages(ctx context.Context, image string) ([]string, error)
+}
+
+// imageSearches are the built-in image search backends by name, the API based ones use
+// Config.ImageSearchKey.
+var imageSearches = map[string]func(c *Client) ImageSearch{
+	"lens":    func(c *Client) ImageSearch { return lens{c} },
+	"serpapi": func(c *Client) ImageSearch { return serpAPI{c} },
+	"bing":    func(c *Client) ImageSearch { return bingVisual{c} },
+	"tineye":  func(c *Client) ImageSearch { return tinEye{c} },
+}
+
+// errNoKey is returned by the API based image searches without a key.
+var errNoKey = errors.New("image search API key is missing")
+
+// ImageSearches returns the names of the built-in image search backends.
+func ImageSearches() []string {
+	names := make([]string, 0, len(imageSearches))
+	for name := range imageSearches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckImageSearch returns an error if there's no built-in image search backend by the name.
+func CheckImageSearch(name string) error {
+	if _, ok := imageSearches[name]; !ok {
+		return fmt.Errorf("unknown image search %q, use one of %s", name, strings.Join(ImageSearches(), ", "))
+	}
+	return nil
+}
+
+// SetImageSearch replaces the image search backend of the client.
+func (c *Client) SetImageSearch(s ImageSearch) {
+	c.img = s
+}
+
+// SearchByImage searches the pages showing the logo URL by the image search backend, and returns
+// up to 3 distinct result links. The matches have no name.
+func (c *Client) SearchByImage(ctx context.Context, logo string) ([]Match, error) {
+	c.counters.imageSearches.Add(1)
+	links, err := c.img.Pages(ctx, logo)
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	m := map[string]struct{}{} // keep track of duplicated links
+	for _, link := range links {
+		if _, ok := m[link]; ok || len(link) == 0 {
+			continue
+		}
+		m[link] = struct{}{}
+		if matches = append(matches, Match{Link: link}); len(matches) == imageResults {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// apiDo does the API request and decodes the JSON response to v. API responses aren't cached.
+func (c *Client) apiDo(req *http.Request, v any) error {
+	if err := c.rates[rateImage].wait(req.Context()); err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	progressed(req.Context())
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("wrong status from %s: %s %s", req.URL.Host, resp.Status, b)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
+
+// serpAPI searches Google Lens by SerpAPI.
+type serpAPI struct {
+	c *Client
+}
+
+func (s serpAPI) Pages(ctx context.Context, image string) ([]string, error) {
+	key := s.c.cfg.ImageSearchKey
+	if len(key) == 0 {
+		return nil, errNoKey
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://serpapi.com/search.json?engine=google_lens&url="+
+		url.QueryEscape(image)+"&api_key="+url.QueryEscape(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		VisualMatches []struct {
+			Link string `json:"link"`
+		} `json:"visual_matches"`
+	}
+	if err = s.c.apiDo(req, &res); err != nil {
+		return nil, err
+	}
+	links := make([]string, len(res.VisualMatches))
+	for i, m := range res.VisualMatches {
+		links[i] = m.Link
+	}
+	return links, nil
+}
+
+// bingVisual searches by Bing Visual Search.
+type bingVisual struct {
+	c *Client
+}
+
+func (b bingVisual) Pages(ctx context.Context, image string) ([]string, error) {
+	key := b.c.cfg.ImageSearchKey
+	if len(key) == 0 {
+		return nil, errNoKey
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	q, err := json.Marshal(map[string]any{"imageInfo": map[string]string{"url": image}})
+	if err == nil {
+		err = mw.WriteField("knowledgeRequest", string(q))
+	}
+	if err == nil {
+		err = mw.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.bing.microsoft.com/v7.0/images/visualsearch", &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("content-type", mw.FormDataContentType())
+	req.Header.Set("ocp-apim-subscription-key", key)
+	var res struct {
+		Tags []struct {
+			Actions []struct {
+				ActionType string `json:"actionType"`
+				Data       struct {
+					Value []struct {
+						HostPageURL string `json:"hostPageUrl"`
+					} `json:"value"`
+				} `json:"data"`
+			} `json:"actions"`
+		} `json:"tags"`
+	}
+	if err = b.c.apiDo(req, &res); err != nil {
+		return nil, err
+	}
+	var links []string
+	for _, t := range res.Tags {
+		for _, a := range t.Actions {
+			if a.ActionType != "PagesIncluding" {
+				continue
+			}
+			for _, v := range a.Data.Value {
+				links = append(links, v.HostPageURL)
+			}
+		}
+	}
+	return links, nil
+}
+
+// tinEye searches by the TinEye API.
+type tinEye struct {
+	c *Client
+}
+
+func (t tinEye) Pages(ctx context.Context, image string) ([]string, error) {
+	key := t.c.cfg.ImageSearchKey
+	if len(key) == 0 {
+		return nil, errNoKey
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.tineye.com/rest/search/?image_url="+url.QueryEscape(image), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", key)
+	var res struct {
+		Results struct {
+			Matches []struct {
+				Backlinks []struct {
+					Backlink string `json:"backlink"`
+				} `json:"backlinks"`
+			} `json:"matches"`
+		} `json:"results"`
+	}
+	if err = t.c.apiDo(req, &res); err != nil {
+		return nil, err
+	}
+	var links []string
+	for _, m := range res.Results.Matches {
+		for _, bl := range m.Backlinks {
+			links = append(links, bl.Backlink)
+		}
+	}
+	return links, nil
+}
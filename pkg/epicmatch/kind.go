@@ -0,0 +1,275 @@
+package epicmatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Kinds of the search results, see Match.Kind.
+const (
+	KindGame       = "game"
+	KindDLC        = "dlc"
+	KindAddon      = "addons"
+	KindEdition    = "editions"
+	KindDemo       = "demos"
+	KindSoundtrack = "soundtracks"
+)
+
+// storeKinds are the kinds by the result types of the store, folded and letters only.
+var storeKinds = map[string]string{
+	"basegame":   KindGame,
+	"game":       KindGame,
+	"dlc":        KindDLC,
+	"addon":      KindAddon,
+	"addons":     KindAddon,
+	"edition":    KindEdition,
+	"editions":   KindEdition,
+	"bundle":     KindEdition,
+	"demo":       KindDemo,
+	"gamedemo":   KindDemo,
+	"soundtrack": KindSoundtrack,
+}
+
+// Product categories of the store, see Config.Types.
+const (
+	TypeGames   = "games"
+	TypeEditors = "editors"
+	TypeApps    = "apps"
+)
+
+// storeCategories are the categories by the result types of the store, folded and letters only,
+// the other types are of games.
+var storeCategories = map[string]string{
+	"editor":      TypeEditors,
+	"editors":     TypeEditors,
+	"app":         TypeApps,
+	"apps":        TypeApps,
+	"application": TypeApps,
+	"software":    TypeApps,
+}
+
+// categoryFacets are the category filters of the browse page of the store by category.
+var categoryFacets = map[string]string{TypeGames: "Game", TypeEditors: "Editor", TypeApps: "Software"}
+
+// The preferred results of the same base game, see Prefer.
+const (
+	PreferBase    = "base"
+	PreferEdition = "edition"
+	PreferBundle  = "bundle"
+)
+
+// nameKinds are the kinds by words of the names, when the store doesn't tell the type.
+var nameKinds = []struct{ word, kind string }{
+	{"soundtrack", KindSoundtrack}, {"ost", KindSoundtrack},
+	{"season pass", KindDLC}, {"dlc", KindDLC}, {"expansion", KindDLC},
+	{"demo", KindDemo},
+}
+
+var (
+	// plainName is the similarity pipeline to compare names of the results.
+	plainName = Similarity{Fold: true, Punct: true}
+	// baseName is the similarity pipeline to tell the base name of editions.
+	baseName = Similarity{Fold: true, Punct: true, Editions: true}
+)
+
+// ParseKinds returns the comma separated kinds of results to exclude: dlc, addons, editions,
+// demos and soundtracks.
+func ParseKinds(list string) ([]string, error) {
+	var kinds []string
+	for _, k := range strings.Split(list, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "":
+		case KindDLC, KindAddon, KindEdition, KindDemo, KindSoundtrack:
+			kinds = append(kinds, k)
+		default:
+			return nil, fmt.Errorf("unknown result kind %q, use dlc, addons, editions, demos or soundtracks", k)
+		}
+	}
+	return kinds, nil
+}
+
+// ParseTypes returns the comma separated product categories to search: games, editors and apps.
+func ParseTypes(list string) ([]string, error) {
+	var types []string
+	for _, t := range strings.Split(list, ",") {
+		switch t = strings.TrimSpace(t); t {
+		case "":
+		case TypeGames, TypeEditors, TypeApps:
+			if !slices.Contains(types, t) {
+				types = append(types, t)
+			}
+		default:
+			return nil, fmt.Errorf("unknown product type %q, use games, editors or apps", t)
+		}
+	}
+	return types, nil
+}
+
+// Category returns the product category of the result by its store type, TypeGames for the
+// results of other stores and without a type.
+func (m *Match) Category() string {
+	if c, ok := storeCategories[m.storeType()]; ok && len(m.Store) == 0 {
+		return c
+	}
+	return TypeGames
+}
+
+// categoryQuery returns the category filter of the browse page by Config.Types, empty for all.
+func (c *Client) categoryQuery() string {
+	if len(c.cfg.Types) == 0 {
+		return ""
+	}
+	facets := make([]string, len(c.cfg.Types))
+	for i, t := range c.cfg.Types {
+		facets[i] = categoryFacets[t]
+	}
+	return "&category=" + url.QueryEscape(strings.Join(facets, "|"))
+}
+
+// Kind returns the kind of the result by its store type, or by its name without a known type,
+// like "Soundtrack" or "Deluxe Edition" at the end.
+func (m *Match) Kind() string {
+	if k, ok := storeKinds[m.storeType()]; ok {
+		return k
+	}
+	plain := plainName.normalize(m.Name)
+	words := " " + plain + " "
+	for _, nk := range nameKinds {
+		if strings.Contains(words, " "+nk.word+" ") {
+			return nk.kind
+		}
+	}
+	if m.Base() != plain {
+		return KindEdition
+	}
+	return KindGame
+}
+
+// storeType returns the store type of the result, folded and letters only.
+func (m *Match) storeType() string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, m.Type)
+}
+
+// IsBundle tells if the result is a bundle of games, by its store type or link.
+func (m *Match) IsBundle() bool {
+	return m.storeType() == "bundle" || strings.Contains(m.Link, "/bundles/")
+}
+
+// is tells if the result is of the preferred kind of Prefer.
+func (m *Match) is(prefer string) bool {
+	switch prefer {
+	case PreferBase:
+		return m.Kind() == KindGame
+	case PreferEdition:
+		return m.Kind() == KindEdition && !m.IsBundle()
+	case PreferBundle:
+		return m.IsBundle()
+	}
+	return false
+}
+
+// Prefer returns the first of the matches of the same base game as m of the preferred kind:
+// PreferBase, PreferEdition or PreferBundle, or m itself without any. Results of other stores are
+// never preferred.
+func Prefer(matches []Match, m Match, prefer string) Match {
+	if m.is(prefer) || len(m.Store) > 0 {
+		return m
+	}
+	base := m.Base()
+	for _, o := range matches {
+		if len(o.Store) == 0 && len(o.Name) > 0 && o.is(prefer) && o.Base() == base {
+			return o
+		}
+	}
+	return m
+}
+
+// BaseGame returns the base game of the edition or bundle m, from the matches if it's there,
+// otherwise by the naive product links of its base name. It's false for a base game, or
+// without finding one.
+func (c *Client) BaseGame(ctx context.Context, matches []Match, m Match) (Match, bool) {
+	if len(m.Store) > 0 || len(m.Name) == 0 || m.Kind() != KindEdition {
+		return Match{}, false
+	}
+	base := m.Base()
+	for _, o := range matches {
+		if len(o.Store) == 0 && o.Kind() == KindGame && o.Base() == base {
+			return o, true
+		}
+	}
+	link, err := c.ResolveExact(ctx, base)
+	if err != nil && !errors.Is(err, ErrRegionLocked) || len(link) == 0 || link == m.Link {
+		return Match{}, false
+	}
+	return Match{Name: base, Link: link, Type: "Base Game"}, true
+}
+
+// Base returns the comparable name of the result without its edition suffix.
+func (m *Match) Base() string {
+	n := baseName.normalize(m.Name)
+	for {
+		// editions are removed only once by normalize
+		b := baseName.normalize(n)
+		if b == n {
+			return n
+		}
+		n = b
+	}
+}
+
+// filter drops the results of the excluded kinds, except for the exact name matches, and the ones
+// of other product categories than Config.Types, then groups editions under their base game.
+func (c *Client) filter(matches []Match, name string) []Match {
+	if len(c.cfg.Types) > 0 {
+		// the facet of the browse page is not known to the fallback parsers of the results
+		matches = slices.DeleteFunc(matches, func(m Match) bool { return !slices.Contains(c.cfg.Types, m.Category()) })
+	}
+	if len(c.cfg.Exclude) > 0 {
+		matches = slices.DeleteFunc(matches, func(m Match) bool {
+			return !SameName(m.Name, name) && slices.Contains(c.cfg.Exclude, m.Kind())
+		})
+	}
+	return GroupEditions(matches)
+}
+
+// GroupEditions moves the editions right after the first other result of the same base name,
+// like the base game, keeping the order otherwise.
+func GroupEditions(matches []Match) []Match {
+	bases, kinds := make([]string, len(matches)), make([]string, len(matches))
+	for i := range matches {
+		bases[i], kinds[i] = matches[i].Base(), matches[i].Kind()
+	}
+	// leads are the first non-edition results of the base names
+	leads := map[string]int{}
+	for i := len(matches) - 1; i >= 0; i-- {
+		if kinds[i] != KindEdition {
+			leads[bases[i]] = i
+		}
+	}
+	grouped := make([]Match, 0, len(matches))
+	used := make([]bool, len(matches))
+	for i := range matches {
+		if lead, ok := leads[bases[i]]; used[i] || ok && lead > i {
+			continue // it follows its lead
+		}
+		used[i] = true
+		grouped = append(grouped, matches[i])
+		for j := range matches {
+			if !used[j] && kinds[j] == KindEdition && bases[j] == bases[i] {
+				used[j] = true
+				grouped = append(grouped, matches[j])
+			}
+		}
+	}
+	return grouped
+}
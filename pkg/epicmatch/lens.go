@@ -0,0 +1,31 @@
+package epicmatch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+var reLens = regexp.MustCompile(`"Show less","See more","Show less Similar images","See more Similar images".*?,"(https?://[^"]+)".*?\[\[.*?,"(https?://[^"]+)".*?\[\[.*?,"(https?://[^"]+)"`)
+
+// lens scrapes the Google Lens page of the image, without an API key.
+type lens struct {
+	c *Client
+}
+
+func (l lens) Pages(ctx context.Context, image string) ([]string, error) {
+	b, err := l.c.getBody(ctx, fmt.Sprintf("https://lens.google.com/uploadbyurl?url=%s&hl=en-CA", url.QueryEscape(image)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read google lens result for %s: %w", image, err)
+	}
+	res := reLens.FindSubmatch(b)
+	if len(res) < 2 {
+		return nil, nil
+	}
+	links := make([]string, 0, len(res)-1)
+	for _, resi := range res[1:] {
+		links = append(links, string(resi))
+	}
+	return links, nil
+}
@@ -0,0 +1,146 @@
+package epicmatch
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDelay is the upper limit of backing off between store requests.
+const maxDelay = time.Minute
+
+// Domains of the requests limited independently, so the ones of a domain don't slow down the
+// others, see Client.rateOf.
+const (
+	rateBrowse  = "browse"  // store search pages
+	rateProduct = "product" // other store pages, like the product pages
+	rateImage   = "image"   // image search backends
+)
+
+// imageHosts are the hosts of the image searches limited by rateImage.
+var imageHosts = []string{"lens.google.com", "serpapi.com", "api.bing.microsoft.com", "api.tineye.com"}
+
+// limiter spaces out store requests. It backs off exponentially when the store answers with a
+// Cloudflare challenge, and slowly recovers to the configured delay on successful requests. Many
+// challenges in a row open its circuit breaker, pausing all requests for a cool-down.
+type limiter struct {
+	mtx   sync.Mutex
+	base  time.Duration // configured delay, never goes below
+	delay time.Duration // current delay between requests
+	next  time.Time     // earliest time of the next request
+	// breakAfter is the number of challenges in a row pausing the requests for cooldown, 0 never
+	// pauses. streak counts them, and paused is the end of the pause.
+	breakAfter int
+	cooldown   time.Duration
+	streak     int
+	paused     time.Time
+}
+
+func newLimiter(delay time.Duration, breakAfter int, cooldown time.Duration) *limiter {
+	return &limiter{base: delay, delay: delay, breakAfter: breakAfter, cooldown: cooldown}
+}
+
+// wait blocks until the next request is allowed or the context is done, and reserves the following
+// slot. The requests waiting when the breaker opens wait for the end of the pause too.
+func (l *limiter) wait(ctx context.Context) error {
+	for {
+		l.mtx.Lock()
+		now := time.Now()
+		at := l.next
+		if at.Before(now) {
+			at = now
+		}
+		if at.Before(l.paused) {
+			at = l.paused
+		}
+		l.next = at.Add(l.delay)
+		l.mtx.Unlock()
+		t := time.NewTimer(time.Until(at))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+		if time.Now().After(l.pausedUntil()) {
+			return nil
+		}
+	}
+}
+
+// challenged doubles the delay after a challenge page, and pauses the requests for the cool-down
+// after breakAfter ones in a row. It returns true if it paused them.
+func (l *limiter) challenged() bool {
+	l.mtx.Lock()
+	l.delay = min(max(l.delay*2, time.Second), maxDelay)
+	delay := l.delay
+	l.streak++
+	pause := l.breakAfter > 0 && l.streak >= l.breakAfter
+	if pause {
+		l.streak = 0
+		l.paused = time.Now().Add(l.cooldown)
+	}
+	l.mtx.Unlock()
+	if pause {
+		slog.Warn("too many challenges in a row, pausing all requests", "challenges", l.breakAfter,
+			"cooldown", l.cooldown)
+		return true
+	}
+	slog.Warn("challenge detected, slowing down", "delay", delay)
+	return false
+}
+
+// passed lowers the delay by a quarter of its distance from the configured one.
+func (l *limiter) passed() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.delay -= (l.delay - l.base) / 4
+	l.streak = 0
+}
+
+// solved ends the challenges in a row by a page got through the solver.
+func (l *limiter) solved() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.streak = 0
+}
+
+// pausedUntil returns the end of the pause of the requests, in the past when not paused.
+func (l *limiter) pausedUntil() time.Time {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.paused
+}
+
+// rateOf returns the limiter of the domain of the link, nil for the domains not limited.
+func (c *Client) rateOf(link string) *limiter {
+	if strings.HasPrefix(link, Host+"/") || strings.HasPrefix(link, catalogAPI+"?") {
+		return c.storeRate(link)
+	}
+	u, err := url.Parse(link)
+	if err == nil && slices.Contains(imageHosts, u.Hostname()) {
+		return c.rates[rateImage]
+	}
+	return nil
+}
+
+// storeRate returns the limiter of the store page, of the product pages for the links of other
+// domains.
+func (c *Client) storeRate(link string) *limiter {
+	if strings.Contains(link, "/browse?") {
+		return c.rates[rateBrowse]
+	}
+	return c.rates[rateProduct]
+}
+
+// setBase changes the configured delay, the current one doesn't go below it.
+func (l *limiter) setBase(delay time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.base = delay
+	l.delay = max(l.delay, delay)
+}
@@ -0,0 +1,107 @@
+package epicmatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxTitlePage is the maximum size of a page read for its title.
+const maxTitlePage = 1 << 20
+
+// ErrInvalidLink is returned by CheckLink for links that aren't pages, or missing ones.
+var ErrInvalidLink = errors.New("invalid link")
+
+// reEpicSlug finds the product slug in the path of a store link of any form, like /p/slug,
+// /en-US/p/slug, /store/en-US/p/slug or the old /en-US/product/slug/home.
+var reEpicSlug = regexp.MustCompile(`^(?:/store)?(?:/[a-zA-Z]{2}(?:-[a-zA-Z0-9]{2,4})?)?/(?:p|product)/([^/?#]+)`)
+
+// CheckLink validates a link typed by the user, and returns its canonical form with the title of
+// the page to confirm it. Epic store links of any form are the product page of the slug in the
+// locale, which must exist. Other links must answer after following their redirects.
+func (c *Client) CheckLink(ctx context.Context, link string) (canonical, title string, err error) {
+	link = strings.TrimSpace(link)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.Contains(u.Hostname(), ".") {
+		return "", "", fmt.Errorf("%w: %s is not a web page link", ErrInvalidLink, link)
+	}
+	switch strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") {
+	case "store.epicgames.com", "epicgames.com":
+		m := reEpicSlug.FindStringSubmatch(u.Path)
+		if m == nil {
+			return "", "", fmt.Errorf("%w: no product in %s", ErrInvalidLink, link)
+		}
+		if canonical, err = c.store.ProductBySlug(ctx, m[1]); err != nil && !errors.Is(err, ErrRegionLocked) {
+			return "", "", fmt.Errorf("failed to check product page of %s: %w", link, err)
+		}
+		if len(canonical) == 0 {
+			return "", "", fmt.Errorf("%w: no product page of %s", ErrInvalidLink, m[1])
+		}
+		// cached by ProductBySlug
+		buf, err := c.epicGet(ctx, canonical)
+		if err != nil {
+			return "", "", err
+		}
+		defer putBuf(buf)
+		return canonical, pageTitle(buf), nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+	req.Header.Set("user-agent", currentBrowser().agent)
+	req.Header.Set("accept-language", c.acceptLanguage())
+	resp, err := c.http.Do(req)
+	progressed(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get %s: %w", link, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", "", fmt.Errorf("%w: %s answered %s", ErrInvalidLink, link, resp.Status)
+	}
+	// the redirects are followed by the client
+	return resp.Request.URL.String(), pageTitle(io.LimitReader(resp.Body, maxTitlePage)), nil
+}
+
+// Delisted tells if the product page of the link is gone from the store, showing its not found
+// page. A page unavailable in the region isn't delisted, nor the links of other sites.
+func (c *Client) Delisted(ctx context.Context, link string) (bool, error) {
+	slug := Slug(link)
+	if len(slug) == 0 {
+		return false, nil
+	}
+	found, err := c.store.ProductBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, ErrRegionLocked) {
+		return false, fmt.Errorf("failed to check product page of %s: %w", link, err)
+	}
+	return len(found) == 0, nil
+}
+
+// WaybackLink returns the link of the latest snapshot of the page in the Wayback Machine, like for
+// a product page gone from the store.
+func WaybackLink(link string) string {
+	return "https://web.archive.org/web/" + link
+}
+
+// pageTitle returns the title of the HTML page, empty if there's none.
+func pageTitle(r io.Reader) string {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return ""
+	}
+	if t, ok := doc.Find(`meta[property="og:title"]`).Attr("content"); ok && len(strings.TrimSpace(t)) > 0 {
+		return strings.TrimSpace(t)
+	}
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
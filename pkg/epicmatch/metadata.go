@@ -0,0 +1,70 @@
+package epicmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+var (
+	reDeveloper = regexp.MustCompile(`"developerDisplayName":"([^"]+)"`)
+	rePublisher = regexp.MustCompile(`"publisherDisplayName":"([^"]+)"`)
+	reReleased  = regexp.MustCompile(`"releaseDate":"([^"]+)"`)
+	reGenre     = regexp.MustCompile(`\{[^{}]*"groupName":"genre"[^{}]*\}`)
+)
+
+// Metadata are the catalog details of a store product.
+type Metadata struct {
+	Developer string `json:"developer,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	// Released is the release date in YYYY-MM-DD format.
+	Released string   `json:"released,omitempty"`
+	Genres   []string `json:"genres,omitempty"`
+}
+
+// Metadata scrapes the developer, publisher, release date and genres from the embedded state of
+// the store product page. Missing details are left empty, it fails only without any.
+func (c *Client) Metadata(ctx context.Context, link string) (*Metadata, error) {
+	buf, err := c.epicGet(ctx, c.productPage(link))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product page %s for metadata: %w", link, err)
+	}
+	defer putBuf(buf)
+	b := buf.Bytes()
+	var md Metadata
+	if m := reDeveloper.FindSubmatch(b); m != nil {
+		md.Developer = unquote(m[1])
+	}
+	if m := rePublisher.FindSubmatch(b); m != nil {
+		md.Publisher = unquote(m[1])
+	}
+	if m := reReleased.FindSubmatch(b); m != nil {
+		if t, err := time.Parse(time.RFC3339, string(m[1])); err == nil {
+			md.Released = t.Format(time.DateOnly)
+		}
+	}
+	for _, obj := range reGenre.FindAll(b, -1) {
+		var tag struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(obj, &tag) == nil && len(tag.Name) > 0 && !slices.Contains(md.Genres, tag.Name) {
+			md.Genres = append(md.Genres, tag.Name)
+		}
+	}
+	if len(md.Developer) == 0 && len(md.Publisher) == 0 && len(md.Released) == 0 && len(md.Genres) == 0 {
+		return nil, fmt.Errorf("no metadata found on %s", link)
+	}
+	return &md, nil
+}
+
+// unquote decodes the escapes of a JSON string value, or returns it as is if it's invalid.
+func unquote(b []byte) string {
+	var s string
+	if json.Unmarshal([]byte(`"`+string(b)+`"`), &s) != nil {
+		return string(b)
+	}
+	return s
+}
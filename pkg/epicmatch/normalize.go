@@ -0,0 +1,41 @@
+package epicmatch
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// nameSymbols are dropped from the names by NormalizeName, or replaced by their plain forms: the
+// trademark signs are dropped, the curly quotes are straightened, and the colons and dashes
+// separate words, so "Name: Subtitle", "Name - Subtitle" and "Name — Subtitle" are the same.
+var nameSymbols = strings.NewReplacer(
+	"™", "", "®", "", "©", "", "℠", "",
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'", "`", "'", "´", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`, "«", `"`, "»", `"`,
+	":", " ", "-", " ", "‐", " ", "‑", " ", "‒", " ", "–", " ", "—", " ", "―", " ", "−", " ",
+)
+
+// NormalizeName returns the name in the form compared by the matching, the same for the input
+// names and the store titles: without trademark signs, with straight quotes, colons and dashes
+// separating words, in the compatible Unicode form, like the full width letters in ASCII, and
+// its words separated by single spaces. The case is kept, see SameName.
+func NormalizeName(name string) string {
+	// NFKC would spell the trademark signs out, so they're dropped first, then the symbols of the
+	// compatible forms, like the full width colon
+	name = norm.NFKC.String(nameSymbols.Replace(name))
+	name = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1 // zero width spaces and joiners
+		}
+		return r
+	}, nameSymbols.Replace(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// SameName tells if the names are the same after NormalizeName, ignoring the case, like the exact
+// name matches of the search results.
+func SameName(a, b string) bool {
+	return strings.EqualFold(NormalizeName(a), NormalizeName(b))
+}
@@ -0,0 +1,290 @@
+package epicmatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Strategies of parsing the search results, see resultParsers.
+const (
+	ParseCSS    = "css"    // the list items of the result grid
+	ParseARIA   = "aria"   // the labeled product links anywhere on the page
+	ParseJSONLD = "jsonld" // the embedded structured data
+)
+
+// resultParsers find the search results on the browse page, tried in order until one finds any,
+// so a store redesign breaking one of them degrades the matching instead of failing it.
+var resultParsers = []struct {
+	name  string
+	parse func(doc *goquery.Document) ([]Match, error)
+}{
+	{ParseCSS, cssResults},
+	{ParseARIA, ariaResults},
+	{ParseJSONLD, jsonLDResults},
+}
+
+// ParseStrategies returns the names of the strategies of parsing the search results, in the order
+// they're tried.
+func ParseStrategies() []string {
+	names := make([]string, len(resultParsers))
+	for i, p := range resultParsers {
+		names[i] = p.name
+	}
+	return names
+}
+
+// searchParser parses the browse pages of the store into search results. It's apart from the
+// client, so saved pages are parsed alone, like by the golden tests of the store layouts.
+type searchParser struct {
+	// parsed counts the pages by the index of their strategy in resultParsers, nil for none.
+	parsed []atomic.Int64
+}
+
+// parse returns the search results of the first strategy finding any, with its name. Without any,
+// the parse error of the first strategy having one is returned with its results, otherwise
+// ErrNoResults.
+func (sp searchParser) parse(doc *goquery.Document) ([]Match, string, error) {
+	var partial []Match
+	var parseErr error
+	for i, p := range resultParsers {
+		matches, err := p.parse(doc)
+		if err == nil && len(matches) > 0 {
+			if sp.parsed != nil {
+				sp.parsed[i].Add(1)
+			}
+			return matches, p.name, nil
+		}
+		if errors.Is(err, ErrParse) && parseErr == nil {
+			partial, parseErr = matches, fmt.Errorf("%s: %w", p.name, err)
+		}
+	}
+	if parseErr != nil {
+		return partial, "", parseErr
+	}
+	return nil, "", ErrNoResults
+}
+
+// cssResults parses the list items of the result grid.
+func cssResults(doc *goquery.Document) ([]Match, error) {
+	lis := doc.Find("section > section > ul")
+	if lis == nil || len(lis.Nodes) == 0 {
+		return nil, fmt.Errorf("no ul element found: %w", ErrNoResults)
+	}
+	if lis = goquery.NewDocumentFromNode(lis.Nodes[0]).Find("li"); lis == nil || len(lis.Nodes) == 0 {
+		return nil, fmt.Errorf("no li elements found: %w", ErrNoResults)
+	}
+	matches := make([]Match, 0, len(lis.Nodes))
+	for i, li := range lis.Nodes {
+		m, err := parseResult(li)
+		if err != nil {
+			return matches, fmt.Errorf("search result %d: %w: %w", i, ErrParse, err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// ariaResults parses the product links labeled by their type and name, wherever they are, with
+// the image of their closest list item or the link itself.
+func ariaResults(doc *goquery.Document) ([]Match, error) {
+	var matches []Match
+	doc.Find(`a[aria-label][href*="/p/"], a[aria-label][href*="/bundles/"]`).Each(func(_ int, a *goquery.Selection) {
+		label, _ := a.Attr("aria-label")
+		href, _ := a.Attr("href")
+		m := Match{Link: absLink(href)}
+		if m.Name, m.Type = parseLabel(label); len(m.Name) == 0 {
+			return
+		}
+		if !slices.ContainsFunc(matches, func(o Match) bool { return o.Link == m.Link }) {
+			item := a.Closest("li")
+			if item.Length() == 0 {
+				item = a
+			}
+			m.Image = thumbnail(item.Nodes[0])
+			matches = append(matches, m)
+		}
+	})
+	return matches, nil
+}
+
+// jsonLDResults parses the games and products of the structured data of the page, like an
+// ItemList of VideoGame items.
+func jsonLDResults(doc *goquery.Document) ([]Match, error) {
+	var matches []Match
+	var decodeErr error
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var v any
+		if err := json.Unmarshal([]byte(s.Text()), &v); err != nil {
+			decodeErr = fmt.Errorf("%w: %w", ErrParse, err)
+			return
+		}
+		walkLD(v, func(obj map[string]any) {
+			name, _ := obj["name"].(string)
+			link, _ := obj["url"].(string)
+			if len(name) == 0 || len(link) == 0 {
+				return
+			}
+			m := Match{Name: name, Link: absLink(link), Image: ldImage(obj["image"])}
+			if !slices.ContainsFunc(matches, func(o Match) bool { return o.Link == m.Link }) {
+				matches = append(matches, m)
+			}
+		})
+	})
+	if len(matches) == 0 && decodeErr != nil {
+		return nil, decodeErr
+	}
+	return matches, nil
+}
+
+// parseResult parses the name, link and thumbnail of the search result list item.
+func parseResult(li *html.Node) (Match, error) {
+	m := Match{Image: thumbnail(li)}
+	li, err := nthChildren(li, nthChild{atom.Div, 1}, nthChild{atom.Div, 1}, nthChild{atom.A, 1})
+	if err != nil {
+		return m, fmt.Errorf("nthChildren failure: %w", err)
+	}
+	for _, at := range li.Attr {
+		switch at.Key {
+		case "aria-label":
+			m.Name, m.Type = parseLabel(at.Val)
+		case "href":
+			m.Link = Host + at.Val
+		}
+	}
+	if len(m.Name) == 0 {
+		return m, fmt.Errorf("aria-label not found in attr %#v", li.Attr)
+	}
+	if len(m.Link) == 0 {
+		return m, fmt.Errorf("href not found in attr %#v", li.Attr)
+	}
+	return m, nil
+}
+
+// parseLabel returns the name and the type of a search result by its aria-label, empty if it has
+// too few parts. The type, like Base Game or Add-On, is right before the name.
+func parseLabel(label string) (name, typ string) {
+	parts := strings.Split(label, ", ")
+	i := 2
+	if len(parts) == 3 {
+		i = 1
+	}
+	if len(parts) <= i {
+		return "", ""
+	}
+	return parts[i], parts[i-1]
+}
+
+// thumbnail returns the first image source under n, preferring the lazy loaded data-image.
+func thumbnail(n *html.Node) string {
+	img := goquery.NewDocumentFromNode(n).Find("img").First()
+	if src, ok := img.Attr("data-image"); ok {
+		return src
+	}
+	src, _ := img.Attr("src")
+	if strings.HasPrefix(src, "data:") {
+		return "" // placeholder before lazy loading
+	}
+	return src
+}
+
+type nthChild struct {
+	tag   atom.Atom
+	index int
+}
+
+// nthChildren loops on the given HTML tag-index pairs, going down the tree for the specified child.
+func nthChildren(n *html.Node, tags ...nthChild) (*html.Node, error) {
+	for i, t := range tags {
+		if n = n.FirstChild; n == nil {
+			return nil, fmt.Errorf("no first child before %dth child %v", i, t.tag)
+		}
+		for j := range t.index - 1 {
+			if n = n.NextSibling; n == nil {
+				return nil, fmt.Errorf("no %dth child before %dth child %v", j, i, t.tag)
+			}
+		}
+		if n.DataAtom != t.tag {
+			return nil, fmt.Errorf("expected tag %s != %s for nth child before %dth child %v", t.tag, n.DataAtom, i, t.tag)
+		}
+	}
+	return n, nil
+}
+
+// productName returns the name of the product in the structured data of its page, empty without
+// it.
+func productName(r io.Reader) string {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return ""
+	}
+	var name string
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var v any
+		if json.Unmarshal([]byte(s.Text()), &v) != nil {
+			return true
+		}
+		walkLD(v, func(obj map[string]any) {
+			if n, _ := obj["name"].(string); len(name) == 0 {
+				name = strings.TrimSpace(n)
+			}
+		})
+		return len(name) == 0
+	})
+	return name
+}
+
+// ldTypes are the structured data types of the search results.
+var ldTypes = []string{"VideoGame", "Product", "SoftwareApplication"}
+
+// walkLD calls found for the objects of ldTypes in the structured data, in document order.
+func walkLD(v any, found func(obj map[string]any)) {
+	switch v := v.(type) {
+	case []any:
+		for _, it := range v {
+			walkLD(it, found)
+		}
+	case map[string]any:
+		if t, ok := v["@type"].(string); ok && slices.Contains(ldTypes, t) {
+			found(v)
+			return
+		}
+		for _, key := range []string{"@graph", "itemListElement", "item"} {
+			if it, ok := v[key]; ok {
+				walkLD(it, found)
+			}
+		}
+	}
+}
+
+// ldImage returns the first image of the structured data image value.
+func ldImage(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			return ldImage(v[0])
+		}
+	case map[string]any:
+		u, _ := v["url"].(string)
+		return u
+	}
+	return ""
+}
+
+// absLink returns the store link of the href relative to the store.
+func absLink(href string) string {
+	if strings.HasPrefix(href, "/") {
+		return Host + href
+	}
+	return href
+}
@@ -0,0 +1,100 @@
+package epicmatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// update rewrites the golden files by the current parser, eg. after adding a saved page.
+var update = flag.Bool("update", false, "rewrite the golden files of testdata/search")
+
+// goldenResult is the outcome of parsing a saved page, kept next to it as page.golden.json.
+type goldenResult struct {
+	Strategy string        `json:"strategy,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Matches  []goldenMatch `json:"matches,omitempty"`
+}
+
+type goldenMatch struct {
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"`
+	Link  string `json:"link"`
+	Image string `json:"image,omitempty"`
+}
+
+// TestSearchParser parses the saved browse pages of testdata/search, each of a layout of the store,
+// and compares the results to their golden files.
+func TestSearchParser(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "search", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no saved pages in testdata/search")
+	}
+	for _, page := range pages {
+		t.Run(strings.TrimSuffix(filepath.Base(page), ".html"), func(t *testing.T) {
+			f, err := os.Open(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			doc, err := goquery.NewDocumentFromReader(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matches, strategy, err := searchParser{}.parse(doc)
+			got := goldenResult{Strategy: strategy}
+			if err != nil {
+				got.Error = err.Error()
+			}
+			for _, m := range matches {
+				got.Matches = append(got.Matches, goldenMatch{Name: m.Name, Type: m.Type, Link: m.Link, Image: m.Image})
+			}
+			b, err := json.MarshalIndent(got, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			b = append(b, '\n')
+			golden := strings.TrimSuffix(page, ".html") + ".golden.json"
+			if *update {
+				if err = os.WriteFile(golden, b, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run go test -run TestSearchParser -update to create it", err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("results of %s differ from %s, got:\n%s", page, golden, b)
+			}
+		})
+	}
+}
+
+func TestParseLabel(t *testing.T) {
+	for _, tc := range []struct {
+		label, name, typ string
+	}{
+		{"Base Game, Hades, $24.99", "Hades", "Base Game"},
+		{"Add-On, Hades Original Soundtrack, Free", "Hades Original Soundtrack", "Add-On"},
+		{"Early Access, Base Game, Hades II, -20%, $29.99, $23.99", "Hades II", "Base Game"},
+		{"New, Base Game, Celeste, $19.99", "Celeste", "Base Game"},
+		{"Base Game, Celeste", "", ""},
+		{"Celeste", "", ""},
+		{"", "", ""},
+	} {
+		if name, typ := parseLabel(tc.label); name != tc.name || typ != tc.typ {
+			t.Errorf("parseLabel(%q) = %q, %q, want %q, %q", tc.label, name, typ, tc.name, tc.typ)
+		}
+	}
+}
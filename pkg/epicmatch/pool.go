@@ -0,0 +1,101 @@
+package epicmatch
+
+import (
+	"bytes"
+	"slices"
+	"sync/atomic"
+)
+
+// bufClasses are the capacities of the pooled buffers by size class. Buffers grown beyond twice
+// their class aren't pooled, so a few huge pages don't stay in memory for good.
+var bufClasses = []int{32 << 10, 256 << 10, 1 << 20, 4 << 20}
+
+const (
+	// pageBuf is the usual size of a store page, the size class of reading pages of unknown size.
+	pageBuf = 256 << 10
+	// defaultPoolBytes is the default maximum capacity of the free buffers of the pool.
+	defaultPoolBytes = 64 << 20
+)
+
+// bufPool keeps the free buffers by size class, each class bounded by the total capacity of its
+// buffers. Unlike a sync.Pool, the free buffers never take more memory than that.
+type bufPool struct {
+	classes []chan *bytes.Buffer
+}
+
+// pool is the pool of the buffers of the responses, nil before the first use, see bufs. It's
+// swapped atomically, as LimitPool may run with requests in flight.
+var pool atomic.Pointer[bufPool]
+
+// bufs returns the pool of the buffers of the responses, the one of defaultPoolBytes without
+// LimitPool.
+func bufs() *bufPool {
+	if p := pool.Load(); p != nil {
+		return p
+	}
+	pool.CompareAndSwap(nil, newBufPool(defaultPoolBytes))
+	return pool.Load()
+}
+
+// newBufPool returns a pool keeping limit bytes of free buffers by their size classes, shared
+// equally by the classes. A buffer is kept up to twice the capacity of its class, so the free
+// buffers take up to twice limit.
+func newBufPool(limit int) *bufPool {
+	p := &bufPool{classes: make([]chan *bytes.Buffer, len(bufClasses))}
+	for i, size := range bufClasses {
+		p.classes[i] = make(chan *bytes.Buffer, limit/len(bufClasses)/size)
+	}
+	return p
+}
+
+// LimitPool bounds the free buffers kept for reuse to n bytes by their size classes, up to twice
+// that by their capacity, like a part of the memory limit of the process. The free buffers of
+// the previous limit are left to the garbage collector.
+func LimitPool(n int64) {
+	pool.Store(newBufPool(int(n)))
+}
+
+// get returns an empty buffer of at least n bytes if one is free, otherwise a new one of the
+// size class of n.
+func (p *bufPool) get(n int) *bytes.Buffer {
+	i, _ := slices.BinarySearch(bufClasses, n)
+	i = min(i, len(bufClasses)-1)
+	for _, free := range p.classes[i:] {
+		select {
+		case b := <-free:
+			b.Reset()
+			return b
+		default:
+		}
+	}
+	return bytes.NewBuffer(make([]byte, 0, max(n, bufClasses[i])))
+}
+
+// Put keeps the buffer for reuse in the largest size class it fits, unless that class is full, or
+// the buffer is too small for any, or grown beyond twice its class. The too big ones are dropped
+// for the garbage collector rather than reallocated into their class: a page that big grows a
+// buffer of the class again anyway, and keeping it would hold its memory.
+func (p *bufPool) Put(b *bytes.Buffer) {
+	n := b.Cap()
+	i, found := slices.BinarySearch(bufClasses, n)
+	if !found {
+		i--
+	}
+	if i < 0 || n > 2*bufClasses[i] {
+		return
+	}
+	select {
+	case p.classes[i] <- b:
+	default:
+	}
+}
+
+// getBuf returns an empty buffer for reading a response of about n bytes, see pageBuf.
+func getBuf(n int) *bytes.Buffer {
+	return bufs().get(n)
+}
+
+// putBuf puts the buffer back to the pool for reuse.
+func putBuf(b *bytes.Buffer) {
+	bufs().Put(b)
+}
@@ -0,0 +1,59 @@
+package epicmatch
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPoolPut keeps the buffers in the largest size class they fit, and drops the ones too small
+// for any or grown beyond twice their class.
+func TestPoolPut(t *testing.T) {
+	largest := bufClasses[len(bufClasses)-1]
+	for _, tc := range []struct {
+		name  string
+		size  int
+		class int // -1 for dropped
+	}{
+		{"below the smallest", bufClasses[0] - 1, -1},
+		{"smallest", bufClasses[0], 0},
+		{"between classes", bufClasses[1] + bufClasses[1]/2, 1},
+		{"up to twice the class", 2 * bufClasses[0], 0},
+		{"beyond twice the class", 2*bufClasses[0] + 1, -1},
+		{"largest", largest, len(bufClasses) - 1},
+		{"up to twice the largest", 2 * largest, len(bufClasses) - 1},
+		{"above the cap", 2*largest + 1, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newBufPool(defaultPoolBytes)
+			p.Put(bytes.NewBuffer(make([]byte, 0, tc.size)))
+			for i, free := range p.classes {
+				want := 0
+				if i == tc.class {
+					want = 1
+				}
+				if len(free) != want {
+					t.Errorf("class %d has %d buffers, want %d", bufClasses[i], len(free), want)
+				}
+			}
+		})
+	}
+}
+
+// TestPoolGet reuses a pooled buffer of a large enough class, and allocates one of the size
+// class of n otherwise.
+func TestPoolGet(t *testing.T) {
+	p := newBufPool(defaultPoolBytes)
+	if b := p.get(bufClasses[1] + 1); b.Cap() != bufClasses[2] {
+		t.Errorf("new buffer has capacity %d, want %d", b.Cap(), bufClasses[2])
+	}
+	pooled := bytes.NewBuffer(make([]byte, 0, bufClasses[2]))
+	pooled.WriteString("stale")
+	p.Put(pooled)
+	b := p.get(bufClasses[0])
+	if b != pooled {
+		t.Fatal("the pooled buffer of a larger class wasn't reused")
+	}
+	if b.Len() != 0 {
+		t.Errorf("reused buffer has %d bytes, want it empty", b.Len())
+	}
+}
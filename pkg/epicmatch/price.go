@@ -0,0 +1,68 @@
+package epicmatch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var rePrice = regexp.MustCompile(`"totalPrice":\{"discountPrice":(\d+),"originalPrice":(\d+),.*?"fmtPrice":\{"originalPrice":"([^"]*)","discountPrice":"([^"]*)"`)
+
+// Price is the current price of a store product.
+type Price struct {
+	Original string `json:"original"`
+	Current  string `json:"current"`
+	// Discount is in percent of the original price.
+	Discount int  `json:"discount"`
+	Free     bool `json:"free"`
+}
+
+// IsProduct returns true for the store product page links of any locale, or without one.
+func IsProduct(link string) bool {
+	path, ok := strings.CutPrefix(link, Host+"/")
+	if !ok {
+		return false
+	}
+	if strings.HasPrefix(path, "p/") {
+		return true
+	}
+	_, path, _ = strings.Cut(path, "/")
+	return strings.HasPrefix(path, "p/")
+}
+
+// productPage returns the link of the product page in the configured country, the same for all
+// details so it's downloaded only once with the cache.
+func (c *Client) productPage(link string) string {
+	if strings.HasPrefix(link, Host+"/p/") {
+		// the store redirects the links without a locale
+		link = CanonicalLink(link, c.cfg.Locale)
+	}
+	sep := "?"
+	if strings.Contains(link, "?") {
+		sep = "&"
+	}
+	return link + c.countryQuery(sep)
+}
+
+// Price scrapes the price from the embedded state of the store product page, in the configured
+// country if any.
+func (c *Client) Price(ctx context.Context, link string) (*Price, error) {
+	buf, err := c.epicGet(ctx, c.productPage(link))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product page %s for price: %w", link, err)
+	}
+	defer putBuf(buf)
+	m := rePrice.FindSubmatch(buf.Bytes())
+	if m == nil {
+		return nil, fmt.Errorf("no price found on %s", link)
+	}
+	current, _ := strconv.Atoi(string(m[1]))
+	original, _ := strconv.Atoi(string(m[2]))
+	p := &Price{Original: string(m[3]), Current: string(m[4]), Free: current == 0}
+	if original > 0 {
+		p.Discount = (original - current) * 100 / original
+	}
+	return p, nil
+}
@@ -0,0 +1,114 @@
+package epicmatch
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyCooldown is how long a failed proxy is skipped.
+const proxyCooldown = 5 * time.Minute
+
+// ErrNoProxy is returned when all configured proxies failed recently.
+var ErrNoProxy = errors.New("all proxies are dead")
+
+// proxyServer is a proxy with its own HTTP client.
+type proxyServer struct {
+	url    string
+	http   *http.Client
+	deadAt time.Time
+}
+
+// proxies rotate the proxies per request, skipping the dead ones for proxyCooldown.
+type proxies struct {
+	mtx  sync.Mutex
+	list []*proxyServer
+	next int
+}
+
+func newProxies(urls []string, timeout time.Duration) *proxies {
+	ps := &proxies{}
+	for _, u := range urls {
+		pu, err := url.Parse(u)
+		if err != nil {
+			slog.Warn("invalid proxy, skipping it", "proxy", u, "err", err)
+			continue
+		}
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.Proxy = http.ProxyURL(pu)
+		ps.list = append(ps.list, &proxyServer{url: u, http: &http.Client{Transport: tr, Timeout: timeout}})
+	}
+	return ps
+}
+
+// get returns the next live proxy, nil without any configured.
+func (ps *proxies) get() (*proxyServer, error) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	if len(ps.list) == 0 {
+		return nil, nil
+	}
+	for range ps.list {
+		p := ps.list[ps.next]
+		ps.next = (ps.next + 1) % len(ps.list)
+		if time.Since(p.deadAt) > proxyCooldown {
+			return p, nil
+		}
+	}
+	return nil, ErrNoProxy
+}
+
+// dead marks the proxy failed, so it's skipped for a while.
+func (ps *proxies) dead(p *proxyServer, err error) {
+	slog.Warn("proxy failed, skipping it for a while", "proxy", p.url, "for", proxyCooldown, "err", err)
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	p.deadAt = time.Now()
+}
+
+// CheckProxy returns an error if the proxy is not an absolute http, https or socks5 URL.
+func CheckProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy %q, use http://, https:// or socks5://host:port", proxy)
+	}
+	if len(u.Host) == 0 {
+		return fmt.Errorf("invalid proxy %q without host", proxy)
+	}
+	return nil
+}
+
+// LoadProxies reads the proxies of the file, one per line. Empty lines and lines starting with #
+// are ignored.
+func LoadProxies(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var list []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err = CheckProxy(line); err != nil {
+			return nil, err
+		}
+		list = append(list, line)
+	}
+	return list, sc.Err()
+}
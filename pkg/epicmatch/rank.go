@@ -0,0 +1,229 @@
+package epicmatch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gogf/gf/text/gstr"
+)
+
+var seps = []byte(":- ")
+
+// Similarity is the pipeline of comparing the searched name with the search results, after
+// NormalizeName. The zero value is the bare Levenshtein distance of the normalized names.
+type Similarity struct {
+	Romanize bool // transliteration of localized names, see Romanize
+	Fold     bool // case folding
+	Punct    bool // punctuation stripping
+	Editions bool // edition suffix removal, like Deluxe Edition or GOTY
+	Numerals bool // Roman numerals to Arabic ones
+	TokenSet bool // token set ratio instead of the Levenshtein distance, ignoring the word order
+}
+
+// similaritySteps are the steps of the similarity pipeline by name.
+var similaritySteps = map[string]func(s *Similarity){
+	"romanize": func(s *Similarity) { s.Romanize = true },
+	"fold":     func(s *Similarity) { s.Fold = true },
+	"punct":    func(s *Similarity) { s.Punct = true },
+	"editions": func(s *Similarity) { s.Editions = true },
+	"numerals": func(s *Similarity) { s.Numerals = true },
+	"tokenset": func(s *Similarity) { s.TokenSet = true },
+}
+
+// FullSimilarity has all steps of the similarity pipeline.
+var FullSimilarity = Similarity{Romanize: true, Fold: true, Punct: true, Editions: true, Numerals: true,
+	TokenSet: true}
+
+// ParseSimilarity returns the similarity pipeline of the comma separated steps: romanize, fold,
+// punct, editions, numerals and tokenset. "all" is FullSimilarity, "levenshtein" is the zero value.
+func ParseSimilarity(list string) (Similarity, error) {
+	var s Similarity
+	for _, step := range strings.Split(list, ",") {
+		switch step = strings.TrimSpace(step); step {
+		case "", "levenshtein":
+		case "all":
+			s = FullSimilarity
+		default:
+			set, ok := similaritySteps[step]
+			if !ok {
+				return s, fmt.Errorf("unknown similarity step %q, use romanize, fold, punct, editions, numerals, "+
+					"tokenset, all or levenshtein", step)
+			}
+			set(&s)
+		}
+	}
+	return s, nil
+}
+
+// editions are the suffixes of the names removed by the editions step, folded, without
+// punctuation. They're removed in this order, so a suffix comes before the shorter ones it ends
+// with, like "digital deluxe edition" before "deluxe edition".
+var editions = []string{
+	"game of the year edition", "game of the year", "goty edition", "goty",
+	"digital deluxe edition", "deluxe edition", "definitive edition", "complete edition", "ultimate edition",
+	"gold edition", "standard edition", "special edition", "enhanced edition", "directors cut",
+	"edition", "complete bundle", "bundle",
+}
+
+// romans are the Roman numerals converted by the numerals step.
+var romans = map[string]string{
+	"ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6", "vii": "7", "viii": "8", "ix": "9", "x": "10",
+	"xi": "11", "xii": "12", "xiii": "13", "xiv": "14", "xv": "15", "xvi": "16",
+}
+
+// normalize applies the steps of the pipeline before comparing the name.
+func (s Similarity) normalize(name string) string {
+	name = NormalizeName(name)
+	if s.Romanize {
+		name = Romanize(name)
+	}
+	if s.Fold {
+		name = strings.ToLower(name)
+	}
+	if s.Punct {
+		name = strings.Join(strings.FieldsFunc(strings.ReplaceAll(name, "'", ""), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}), " ")
+	}
+	if s.Editions {
+		for _, e := range editions {
+			cut := len(name) - len(e)
+			if cut > 0 && name[cut-1] == ' ' && strings.EqualFold(name[cut:], e) {
+				name = strings.TrimSpace(name[:cut])
+			}
+		}
+	}
+	if s.Numerals {
+		words := strings.Fields(name)
+		for i, w := range words {
+			if n, ok := romans[strings.ToLower(w)]; ok && i > 0 {
+				words[i] = n
+			}
+		}
+		name = strings.Join(words, " ")
+	}
+	return name
+}
+
+// titleName is the similarity pipeline of confirming the title of a product page, see sameTitle.
+var titleName = Similarity{Romanize: true, Fold: true, Punct: true, Editions: true, Numerals: true}
+
+// sameTitle tells if the names are of the same game, also with a leading article dropped and "&"
+// spelled out, like the slug variants of ResolveExact.
+func sameTitle(a, b string) bool {
+	key := func(n string) string {
+		n = titleName.normalize(strings.ReplaceAll(n, "&", " and "))
+		for _, article := range []string{"the ", "a "} {
+			n = strings.TrimPrefix(n, article)
+		}
+		return n
+	}
+	return key(a) == key(b)
+}
+
+// rankBy ranks the match by the searched name.
+func (m *Match) rankBy(name string, sim Similarity) {
+	a, b := sim.normalize(m.Name), sim.normalize(name)
+	m.Rank = 0
+	// substrings come first
+	switch {
+	case subAny(a, b):
+		m.Confidence = confidence(b, a, 0)
+	case sim.TokenSet:
+		m.Confidence = tokenSetRatio(a, b)
+		m.Rank = 100 - m.Confidence
+	default:
+		// then we rank the list by Levenshtein distance
+		m.Rank = gstr.Levenshtein(a, b, 1, 1, 1)
+		m.Confidence = confidence(b, a, m.Rank)
+	}
+}
+
+// Confidence returns how similar the found name is to the name by the pipeline in 0-100, like
+// the confidence of a search result.
+func (s Similarity) Confidence(name, found string) int {
+	m := Match{Name: found}
+	m.rankBy(name, s)
+	return m.Confidence
+}
+
+// confidence normalizes the rank of a search result to 0-100, 100 being the same name.
+func confidence(name, found string, rank int) int {
+	longer, shorter := len(name), len(found)
+	if longer < shorter {
+		longer, shorter = shorter, longer
+	}
+	if longer == 0 {
+		return 0
+	}
+	if rank == 0 {
+		// substring: the more of the longer name is covered, the better
+		return shorter * 100 / longer
+	}
+	return max(0, 100-rank*100/longer)
+}
+
+// ratio is the Levenshtein similarity of the strings in 0-100.
+func ratio(a, b string) int {
+	if len(a) == 0 && len(b) == 0 {
+		return 100
+	}
+	return confidence(a, b, max(1, gstr.Levenshtein(a, b, 1, 1, 1)))
+}
+
+// tokenSetRatio compares the sorted common words of the names with and without the rest of
+// their words, so the word order and the extra words of the longer name matter less.
+func tokenSetRatio(a, b string) int {
+	ta, tb := tokenSet(a), tokenSet(b)
+	var common, onlyA, onlyB []string
+	for _, t := range ta {
+		if _, ok := slices.BinarySearch(tb, t); ok {
+			common = append(common, t)
+		} else {
+			onlyA = append(onlyA, t)
+		}
+	}
+	for _, t := range tb {
+		if _, ok := slices.BinarySearch(ta, t); !ok {
+			onlyB = append(onlyB, t)
+		}
+	}
+	base := strings.Join(common, " ")
+	withA := strings.TrimSpace(base + " " + strings.Join(onlyA, " "))
+	withB := strings.TrimSpace(base + " " + strings.Join(onlyB, " "))
+	if withA == withB {
+		return 100
+	}
+	best := ratio(withA, withB)
+	if len(common) > 0 {
+		best = max(best, ratio(base, withA), ratio(base, withB))
+	}
+	return best
+}
+
+// tokenSet returns the sorted distinct words of the string.
+func tokenSet(s string) []string {
+	words := strings.Fields(s)
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+// subAny returns true if any of the strings is the substring of the other.
+func subAny(a, b string) bool {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	return strings.Contains(a, b)
+}
+
+// ShortName cuts game end after the first :, - or ' ' character, for a fuzzy search.
+func ShortName(name string) (string, error) {
+	for _, s := range seps {
+		if idx := strings.IndexByte(name, s); idx > -1 {
+			return strings.TrimSpace(name[:idx]), nil
+		}
+	}
+	return "", fmt.Errorf("%s: no separator found in %s", name, seps)
+}
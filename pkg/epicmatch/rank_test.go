@@ -0,0 +1,39 @@
+package epicmatch
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestEditionsOrder checks that no suffix of editions is removed before a longer one ending with
+// it, which could never match then.
+func TestEditionsOrder(t *testing.T) {
+	for i, e := range editions {
+		for _, longer := range editions[i+1:] {
+			if strings.HasSuffix(longer, " "+e) {
+				t.Errorf("edition suffix %q comes before %q", e, longer)
+			}
+		}
+	}
+}
+
+// TestEditionsStep removes the edition suffixes of overlapping names by the editions step.
+func TestEditionsStep(t *testing.T) {
+	sim := Similarity{Fold: true, Punct: true, Editions: true}
+	for _, tc := range []struct{ name, want string }{
+		{"Foo Digital Deluxe Edition", "foo"},
+		{"Foo Deluxe Edition", "foo"},
+		{"Foo Game of the Year Edition", "foo"},
+		{"Foo Game of the Year", "foo"},
+		{"Foo GOTY Edition", "foo"},
+		{"Foo GOTY", "foo"},
+		{"Foo Complete Bundle", "foo"},
+		{"Foo Bundle", "foo"},
+		{"Foo Edition", "foo"},
+		{"Edition", "edition"},
+	} {
+		if got := sim.normalize(tc.name); got != tc.want {
+			t.Errorf("normalize(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package epicmatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// indexFile lists the recorded links by their file names, for finding them by hand.
+const indexFile = "index.tsv"
+
+// recorder saves the responses of the wrapped fetcher to a directory.
+type recorder struct {
+	dir string
+	f   Fetcher
+	mtx sync.Mutex
+}
+
+// Record returns a fetcher saving the responses of f to dir, for replaying them by Replay.
+func Record(dir string, f Fetcher) Fetcher {
+	return &recorder{dir: dir, f: f}
+}
+
+func (r *recorder) Fetch(ctx context.Context, link string, store bool) (io.ReadCloser, error) {
+	body, err := r.f.Fetch(ctx, link, store)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", link, err)
+	}
+	if err = r.save(link, b); err != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", link, err)
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+// save writes the response of the link to the directory, and adds it to the index the first
+// time.
+func (r *recorder) save(link string, b []byte) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+	name := linkHash(link)
+	path := filepath.Join(r.dir, name)
+	_, err := os.Stat(path)
+	recorded := err == nil
+	if err = os.WriteFile(path, b, 0644); err != nil || recorded {
+		// a fetched again link is in the index already
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, indexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s\t%s\n", name, link)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// replayer serves the recorded responses.
+type replayer struct {
+	dir string
+}
+
+// ErrNotRecorded is returned by the Replay fetcher for links without a recorded response.
+var ErrNotRecorded = errors.New("response not recorded")
+
+// Replay returns a fetcher serving the responses recorded to dir by Record, without network
+// access, so the matching can be regression tested.
+func Replay(dir string) Fetcher {
+	return replayer{dir: dir}
+}
+
+func (r replayer) Fetch(_ context.Context, link string, _ bool) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(r.dir, linkHash(link)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotRecorded, link)
+	}
+	return f, err
+}
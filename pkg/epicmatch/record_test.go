@@ -0,0 +1,74 @@
+package epicmatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReplay resolves the names by the search pages recorded to testdata/replay, without network
+// access.
+func TestReplay(t *testing.T) {
+	c := New(Config{Replay: filepath.Join("testdata", "replay"), CacheDir: t.TempDir()})
+	for _, tc := range []struct {
+		name, link string
+	}{
+		{"Hades", "https://store.epicgames.com/en-US/p/hades"},
+		{"Control", "https://store.epicgames.com/en-US/p/control"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := c.Search(context.Background(), tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(matches) == 0 {
+				t.Fatal("no search results")
+			}
+			if m := matches[0]; m.Link != tc.link || m.Confidence != 100 {
+				t.Errorf("best match is %s with confidence %d, want %s with 100", m.Link, m.Confidence, tc.link)
+			}
+		})
+	}
+	if _, err := c.Search(context.Background(), "Celeste"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("search of a name not recorded failed with %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+// staticFetcher answers the same body for every link.
+type staticFetcher string
+
+func (f staticFetcher) Fetch(context.Context, string, bool) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(f))), nil
+}
+
+// TestRecordIndex lists a link fetched again once in the index, with its last response saved.
+func TestRecordIndex(t *testing.T) {
+	dir := t.TempDir()
+	const link = "https://store.epicgames.com/en-US/p/hades"
+	for _, body := range []string{"first", "second"} {
+		rc, err := Record(dir, staticFetcher(body)).Fetch(context.Background(), link, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc.Close()
+	}
+	index, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(index, []byte(link)); n != 1 {
+		t.Errorf("the index lists the link %d times, want once", n)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, linkHash(link)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "second" {
+		t.Errorf("recorded %q, want the last response", b)
+	}
+}
@@ -0,0 +1,132 @@
+package epicmatch
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// kana are the Hepburn romanizations of the hiragana, katakana being mapped to them.
+var kana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+}
+
+// smallKana merge with the kana before them, the small ya, yu and yo like きゃ to kya, and the
+// small vowels replacing its vowel like ファ to fa.
+var smallKana = map[rune]string{
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+}
+
+// cyrillic are the romanizations of the Cyrillic letters of Russian and Ukrainian, lower case.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "yo", 'є': "ye", 'ж': "zh",
+	'з': "z", 'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// greek are the romanizations of the Greek letters, lower case.
+var greek = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Romanize returns the name in Latin letters for comparing localized titles: full width forms
+// and accents are dropped, kana, Cyrillic and Greek letters are transliterated. Other scripts,
+// like kanji, are kept.
+func Romanize(name string) string {
+	var sb strings.Builder
+	// NFKD splits the accents and the voicing marks of kana, the known letters are composed back
+	runes := []rune(norm.NFKD.String(name))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if i+1 < len(runes) && unicode.Is(unicode.Mn, runes[i+1]) {
+			if c := []rune(norm.NFC.String(string(runes[i : i+2]))); len(c) == 1 && known(c[0]) {
+				r = c[0]
+				i++
+			}
+		}
+		lower := toHiragana(unicode.ToLower(r))
+		switch {
+		case lower == 'っ':
+			// the small tsu doubles the next consonant
+			if i+1 < len(runes) {
+				if next := kana[toHiragana(runes[i+1])]; len(next) > 1 {
+					sb.WriteByte(next[0])
+				}
+			}
+		case r == 'ー':
+			// the long vowel mark repeats the previous vowel
+			if s := sb.String(); len(s) > 0 {
+				sb.WriteByte(s[len(s)-1])
+			}
+		case r == '・':
+			sb.WriteByte(' ')
+		case len(kana[lower]) > 0:
+			rom := kana[lower]
+			if i+1 < len(runes) {
+				if y, ok := smallKana[toHiragana(runes[i+1])]; ok && len(rom) > 1 {
+					// kya, but sha, cha and ja
+					base := rom[:len(rom)-1]
+					if len(y) > 1 && (strings.HasSuffix(base, "h") || base == "j") {
+						y = y[1:]
+					}
+					rom = base + y
+					i++
+				}
+			}
+			sb.WriteString(rom)
+		default:
+			rom, ok := cyrillic[lower]
+			if !ok {
+				rom, ok = greek[lower]
+			}
+			switch {
+			case !ok:
+				sb.WriteRune(r)
+			case unicode.IsUpper(r) && len(rom) > 0:
+				sb.WriteString(strings.ToUpper(rom[:1]) + rom[1:])
+			default:
+				sb.WriteString(rom)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// known tells if the letter is transliterated by Romanize.
+func known(r rune) bool {
+	lower := toHiragana(unicode.ToLower(r))
+	_, isKana := kana[lower]
+	_, isCyrillic := cyrillic[lower]
+	_, isGreek := greek[lower]
+	return isKana || isCyrillic || isGreek
+}
+
+// toHiragana maps the katakana to hiragana, and returns other runes as is.
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 0x60
+	}
+	return r
+}
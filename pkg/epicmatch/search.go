@@ -0,0 +1,235 @@
+package epicmatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var reRepl = regexp.MustCompile(`\W+`)
+
+// ProductLink returns the product page link of the slug in the store.
+func (c *Client) ProductLink(slug string) string {
+	return c.store.ProductURL(strings.Trim(slug, "/"))
+}
+
+// SearchLink returns the Epic store search page of the name, for the browser.
+func (c *Client) SearchLink(name string) string {
+	return fmt.Sprintf("%s/%s/browse?q=%s&sortBy=relevancy&sortDir=DESC", Host, c.cfg.Locale, url.QueryEscape(name))
+}
+
+// maxSlugs is the maximum number of slug candidates tried by ResolveExact.
+const maxSlugs = 6
+
+// ResolveExact checks if the naive slug of the name, or a variant of it, is an existing product
+// page of the same game by its structured data, and returns it. See ResolveVariant.
+func (c *Client) ResolveExact(ctx context.Context, name string) (string, error) {
+	link, _, err := c.ResolveVariant(ctx, name)
+	return link, err
+}
+
+// ResolveVariant is ResolveExact returning the variant of the name whose slug is the product page
+// too, like "without the edition" for "Control Ultimate Edition" found as control, empty for the
+// naive slug. The variants drop apostrophes, edition suffixes and leading articles, and spell out
+// "&", they're checked at the same time, the first existing one in this order wins. The link of a
+// page unavailable in the region is returned with ErrRegionLocked if there's no available one.
+func (c *Client) ResolveVariant(ctx context.Context, name string) (link, variant string, err error) {
+	candidates := slugCandidates(name)
+	links := make([]string, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i, sc := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			links[i], errs[i] = c.store.ProductBySlug(ctx, sc.slug)
+		}()
+	}
+	wg.Wait()
+	var others []string
+	var locked, lockedVariant string
+	var lockedErr error
+	for i, link := range links {
+		if errors.Is(errs[i], ErrRegionLocked) {
+			// an available variant is better
+			if len(locked) == 0 && c.confirmProduct(ctx, link, name) {
+				locked, lockedVariant, lockedErr = link, candidates[i].variant, errs[i]
+			}
+			errs[i] = nil
+			continue
+		}
+		if len(link) == 0 {
+			continue
+		}
+		if c.confirmProduct(ctx, link, name) {
+			return link, candidates[i].variant, nil
+		}
+		others = append(others, link)
+	}
+	if len(locked) > 0 {
+		return locked, lockedVariant, lockedErr
+	}
+	if err := errors.Join(errs...); err != nil {
+		return "", "", fmt.Errorf("failed to get request with naaive links of %s: %w", name, err)
+	}
+	if len(others) > 0 {
+		return "", "", fmt.Errorf("naive links of %s are other games: %s", name, strings.Join(others, ", "))
+	}
+	slugs := make([]string, len(candidates))
+	for i, sc := range candidates {
+		slugs[i] = sc.slug
+	}
+	return "", "", fmt.Errorf("naaive links don't work for %s, tried %s", name, strings.Join(slugs, ", "))
+}
+
+// confirmProduct tells if the product page of the link is of the name by the structured data of
+// the page, so the slug of another game isn't taken for it. Pages without the data, or failing to
+// load, are taken as they are.
+func (c *Client) confirmProduct(ctx context.Context, link, name string) bool {
+	if !IsProduct(link) {
+		return true
+	}
+	// cached by ProductBySlug
+	buf, err := c.epicGet(ctx, link)
+	if err != nil {
+		return true
+	}
+	defer putBuf(buf)
+	title := productName(buf)
+	if len(title) == 0 || sameTitle(title, name) {
+		return true
+	}
+	slog.Debug("naive link is another game", "name", name, "link", link, "title", title)
+	return false
+}
+
+// slugEditions are the suffixes also dropped from the names for their slugs, besides editions.
+// They're not editions for ranking, a remaster is another product, but the store often has the
+// remastered or the ultimate form of a game by the slug of the game.
+var slugEditions = []string{"remastered", "remaster", "ultimate", "definitive", "complete"}
+
+// slugVariant is a variant of the names of the slug candidates, see slugCandidates.
+type slugVariant struct {
+	desc  string
+	apply func(string) string
+}
+
+var slugVariants = []slugVariant{
+	{"without apostrophes", func(n string) string { return strings.NewReplacer("'", "", "’", "").Replace(n) }},
+	{`with "and"`, func(n string) string { return strings.ReplaceAll(n, "&", " and ") }},
+	{"without the edition", dropEdition},
+	{"without the article", func(n string) string {
+		lower := strings.ToLower(n)
+		for _, article := range []string{"the ", "a "} {
+			if strings.HasPrefix(lower, article) {
+				return n[len(article):]
+			}
+		}
+		return n
+	}},
+}
+
+// slugCandidate is a slug of the name, with what its variant changed of it.
+type slugCandidate struct {
+	slug    string
+	variant string // descriptions of the applied slugVariants, empty for the naive slug
+}
+
+// slugCandidates returns the naive slug of the name first, then its variants, without duplicates.
+func slugCandidates(name string) []slugCandidate {
+	names := []slugCandidate{{slug: NormalizeName(name)}}
+	for _, v := range slugVariants {
+		for _, n := range names {
+			if changed := v.apply(n.slug); naiveSlug(changed) != naiveSlug(n.slug) {
+				desc := v.desc
+				if len(n.variant) > 0 {
+					desc = n.variant + ", " + desc
+				}
+				names = append(names, slugCandidate{slug: changed, variant: desc})
+			}
+		}
+	}
+	var candidates []slugCandidate
+	for _, n := range names {
+		slug := naiveSlug(n.slug)
+		if len(slug) > 0 && !slices.ContainsFunc(candidates, func(c slugCandidate) bool { return c.slug == slug }) {
+			if candidates = append(candidates, slugCandidate{slug, n.variant}); len(candidates) == maxSlugs {
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// naiveSlug returns the name in lower case, with dashes between its words.
+func naiveSlug(name string) string {
+	return strings.Trim(reRepl.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// dropEdition returns the name without its edition suffixes, like "Ultimate Edition" or
+// "GOTY Remastered", as long as there are any, folded.
+func dropEdition(name string) string {
+	n := baseName.normalize(name)
+	for {
+		b := baseName.normalize(n)
+		for _, e := range slugEditions {
+			if cut := len(b) - len(e); cut > 0 && b[cut-1] == ' ' && b[cut:] == e {
+				b = strings.TrimSpace(b[:cut])
+			}
+		}
+		if b == n {
+			return n
+		}
+		n = b
+	}
+}
+
+// Search searches the store for the name, and returns the results ranked by similarity to it,
+// substrings first, without the excluded kinds and editions grouped. On parse errors the matches
+// found so far are returned with the error.
+func (c *Client) Search(ctx context.Context, name string) ([]Match, error) {
+	matches, err := c.store.Search(ctx, name)
+	if ls, ok := c.store.(LocaleStore); ok && len(c.cfg.Locales) > 0 && (err == nil || errors.Is(err, ErrNoResults)) &&
+		!slices.ContainsFunc(matches, func(m Match) bool { return SameName(m.Name, name) }) {
+		matches, err = c.searchLocales(ctx, ls, name, matches, err)
+	}
+	return c.filter(rank(matches, name, c.cfg.Similarity), name), err
+}
+
+// searchLocales adds the new results of the other locales to the matches, clearing ErrNoResults
+// if there's any.
+func (c *Client) searchLocales(ctx context.Context, ls LocaleStore, name string, matches []Match,
+	err error) ([]Match, error) {
+	for _, locale := range c.cfg.Locales {
+		more, lerr := ls.SearchLocale(ctx, locale, name)
+		if lerr != nil && !errors.Is(lerr, ErrNoResults) {
+			return matches, fmt.Errorf("failed to search in %s: %w", locale, lerr)
+		}
+		for _, m := range more {
+			if !slices.ContainsFunc(matches, func(o Match) bool { return o.Link == m.Link }) {
+				matches = append(matches, m)
+			}
+		}
+	}
+	if len(matches) > 0 {
+		err = nil
+	}
+	return matches, err
+}
+
+// rank fills in the rank and confidence of the matches by the name, and sorts them by rank keeping
+// the store order for equal ranks.
+func rank(matches []Match, name string, sim Similarity) []Match {
+	for i := range matches {
+		matches[i].rankBy(name, sim)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Rank < matches[j].Rank })
+	return matches
+}
@@ -0,0 +1,158 @@
+package epicmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// geForceNowList is the public list of the games of GeForce NOW, an entry per store of a game.
+	geForceNowList = "https://static.nvidiagrid.net/supported-public-game-list/locales/gfnpc-en-US.json"
+	// gamePassList lists the product IDs of a Game Pass catalog, gamePassProducts gets their
+	// titles by gamePassBatch IDs at a time.
+	gamePassList     = "https://catalog.gamepass.com/sigls/v2?id=%s&language=%s&market=%s"
+	gamePassProducts = "https://displaycatalog.mp.microsoft.com/v7.0/products?bigIds=%s&market=%s&languages=%s"
+	gamePassBatch    = 20
+)
+
+// gamePassCatalogs are the IDs of the PC and the console catalogs of Game Pass.
+var gamePassCatalogs = []string{"fdd9e2a7-0fee-49f6-ad69-4354098401ff", "f6f1f99f-9b49-4ccd-b3bf-4d9767a77f5e"}
+
+// gamePassSuffixes are the platform marks of the Game Pass titles, cut for matching the names.
+var gamePassSuffixes = []string{" for Windows 10", " for Windows", " (PC)", " (Windows)", " - Windows Edition"}
+
+// ServiceTitle is a game of a streaming or subscription service.
+type ServiceTitle struct {
+	Title string
+	// Stores are the stores of the copies the service streams, like Steam or Epic Games Store,
+	// empty for a subscription including the game.
+	Stores []string
+}
+
+// service lists the games of a streaming or subscription service by its public catalog.
+type service struct {
+	name   string // shown on the badge of the card
+	titles func(c *Client, ctx context.Context) ([]ServiceTitle, error)
+}
+
+var services = map[string]service{
+	"geforce-now": {name: "GeForce NOW", titles: (*Client).geForceNowTitles},
+	"game-pass":   {name: "Game Pass", titles: (*Client).gamePassTitles},
+}
+
+// ServiceName returns the display name of the service by its key, eg. "geforce-now".
+func ServiceName(key string) (string, error) {
+	s, ok := services[key]
+	if !ok {
+		return "", fmt.Errorf("unknown service %q, use geforce-now or game-pass", key)
+	}
+	return s.name, nil
+}
+
+// ServiceTitles returns the games of the service by its key, eg. "game-pass", from its public
+// catalog.
+func (c *Client) ServiceTitles(ctx context.Context, key string) ([]ServiceTitle, error) {
+	s, ok := services[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown service %q, use geforce-now or game-pass", key)
+	}
+	titles, err := s.titles(c, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the games of %s: %w", s.name, err)
+	}
+	return titles, nil
+}
+
+// geForceNowTitles returns the games of GeForce NOW with the stores of each.
+func (c *Client) geForceNowTitles(ctx context.Context) ([]ServiceTitle, error) {
+	b, err := c.getBody(ctx, geForceNowList)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Title  string `json:"title"`
+		Store  string `json:"store"`
+		Status string `json:"status"`
+	}
+	if err = json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("%w the GeForce NOW games: %w", ErrParse, err)
+	}
+	var titles []ServiceTitle
+	index := map[string]int{}
+	for _, e := range entries {
+		if len(e.Title) == 0 || len(e.Status) > 0 && e.Status != "AVAILABLE" {
+			continue
+		}
+		i, ok := index[e.Title]
+		if !ok {
+			i = len(titles)
+			index[e.Title] = i
+			titles = append(titles, ServiceTitle{Title: e.Title})
+		}
+		if len(e.Store) > 0 {
+			titles[i].Stores = append(titles[i].Stores, e.Store)
+		}
+	}
+	return titles, nil
+}
+
+// gamePassTitles returns the games of the PC and the console catalogs of Game Pass in the market
+// of Config.Country.
+func (c *Client) gamePassTitles(ctx context.Context) ([]ServiceTitle, error) {
+	market, lang := c.catalogCountry(), strings.ToLower(c.cfg.Locale)
+	if len(lang) == 0 {
+		lang = strings.ToLower(DefaultLocale)
+	}
+	var ids []string
+	seen := map[string]bool{}
+	for _, catalog := range gamePassCatalogs {
+		b, err := c.getBody(ctx, fmt.Sprintf(gamePassList, catalog, lang, market))
+		if err != nil {
+			return nil, err
+		}
+		// the first item is the description of the catalog, the others are the product IDs
+		var items []struct {
+			ID string `json:"id"`
+		}
+		if err = json.Unmarshal(b, &items); err != nil {
+			return nil, fmt.Errorf("%w the Game Pass catalog: %w", ErrParse, err)
+		}
+		for _, it := range items {
+			if len(it.ID) > 0 && !seen[it.ID] {
+				seen[it.ID] = true
+				ids = append(ids, it.ID)
+			}
+		}
+	}
+	var titles []ServiceTitle
+	for start := 0; start < len(ids); start += gamePassBatch {
+		batch := ids[start:min(start+gamePassBatch, len(ids))]
+		b, err := c.getBody(ctx, fmt.Sprintf(gamePassProducts, strings.Join(batch, ","), market, lang))
+		if err != nil {
+			return nil, err
+		}
+		var resp struct {
+			Products []struct {
+				LocalizedProperties []struct {
+					ProductTitle string `json:"ProductTitle"`
+				} `json:"LocalizedProperties"`
+			} `json:"Products"`
+		}
+		if err = json.Unmarshal(b, &resp); err != nil {
+			return nil, fmt.Errorf("%w the Game Pass products: %w", ErrParse, err)
+		}
+		for _, p := range resp.Products {
+			if len(p.LocalizedProperties) == 0 || len(p.LocalizedProperties[0].ProductTitle) == 0 {
+				continue
+			}
+			title := p.LocalizedProperties[0].ProductTitle
+			for _, s := range gamePassSuffixes {
+				title = strings.TrimSuffix(title, s)
+			}
+			titles = append(titles, ServiceTitle{Title: title})
+		}
+	}
+	return titles, nil
+}
@@ -0,0 +1,107 @@
+package epicmatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// solverTimeout is the maximum time FlareSolverr has for a challenge.
+const solverTimeout = time.Minute
+
+// clearance is the Cloudflare cookie that lets requests skip the challenge, if any, with the
+// user agent it was issued for.
+type clearance struct {
+	mtx    sync.Mutex
+	cookie string
+	agent  string
+}
+
+func (cl *clearance) get() (cookie, agent string) {
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	return cl.cookie, cl.agent
+}
+
+func (cl *clearance) set(cookie, agent string) {
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	cl.cookie, cl.agent = cookie, agent
+}
+
+// solverAnswer is the used part of a FlareSolverr response.
+type solverAnswer struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Solution struct {
+		Response  string `json:"response"`
+		UserAgent string `json:"userAgent"`
+		Cookies   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"cookies"`
+	} `json:"solution"`
+}
+
+// solve gets the link through FlareSolverr, and keeps the clearance cookie it got for the
+// following requests. The returned buffer should be put back to the pool.
+func (c *Client) solve(ctx context.Context, link string) (*bytes.Buffer, error) {
+	body, err := json.Marshal(map[string]any{
+		"cmd": "request.get", "url": link, "maxTimeout": solverTimeout.Milliseconds(),
+		"cookies": []map[string]string{{"name": ageGateCookie[0], "value": ageGateCookie[1]}}})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, solverTimeout+10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.Solver, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create solver request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	// the solver has its own timeout, longer than the requests
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call solver for %s: %w", link, err)
+	}
+	defer resp.Body.Close()
+	var ans solverAnswer
+	if err = json.NewDecoder(resp.Body).Decode(&ans); err != nil {
+		return nil, fmt.Errorf("failed to decode solver answer for %s: %w", link, err)
+	}
+	if ans.Status != "ok" {
+		return nil, fmt.Errorf("solver failed for %s: %s", link, ans.Message)
+	}
+	for _, ck := range ans.Solution.Cookies {
+		if ck.Name == "cf_clearance" {
+			c.cf.set(ck.Value, ans.Solution.UserAgent)
+			slog.Debug("got clearance from solver")
+		}
+	}
+	buf := getBuf(pageBuf)
+	buf.WriteString(ans.Solution.Response)
+	return buf, nil
+}
+
+// retryAfter returns the wait time of a Retry-After header in seconds or HTTP date format, up
+// to maxDelay. It's 0 if missing or invalid.
+func retryAfter(h string) time.Duration {
+	h = strings.TrimSpace(h)
+	if len(h) == 0 {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(h); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(h); err == nil {
+		d = time.Until(at)
+	}
+	return min(max(d, 0), maxDelay)
+}
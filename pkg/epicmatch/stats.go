@@ -0,0 +1,83 @@
+package epicmatch
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the counters of the requests of a client.
+type Stats struct {
+	// Requests are the network requests, not counting the cache hits.
+	Requests  int
+	CacheHits int
+	// Latency is the total time of the network requests until their response.
+	Latency time.Duration
+	// Challenges are the Cloudflare challenges, each one retried or solved.
+	Challenges int
+	// Pauses are the pauses of all requests after too many challenges in a row.
+	Pauses        int
+	ImageSearches int
+	// CatalogSearches are the lookups in the catalog index with similar titles, without requests.
+	CatalogSearches int
+	// Parsed are the search pages by the strategy parsing their results, see ParseStrategies.
+	Parsed map[string]int
+}
+
+// AvgLatency returns the average latency of the network requests.
+func (s Stats) AvgLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Requests)
+}
+
+// counters are the live Stats of a client.
+type counters struct {
+	requests, cacheHits, latency, challenges, pauses, imageSearches, catalogSearches atomic.Int64
+	// parsed are counted by the index of resultParsers.
+	parsed [3]atomic.Int64
+}
+
+// request counts a network request started at the given time.
+func (cs *counters) request(start time.Time) {
+	cs.requests.Add(1)
+	cs.latency.Add(int64(time.Since(start)))
+}
+
+// progressKey is the context key of the callback of WithProgress.
+type progressKey struct{}
+
+// WithProgress returns a context calling progress after each response of the requests made with
+// it, cached ones included, so a watchdog can tell a slow but progressing lookup from a stuck one.
+func WithProgress(ctx context.Context, progress func()) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// progressed calls the callback of WithProgress of the context, if any.
+func progressed(ctx context.Context) {
+	if progress, ok := ctx.Value(progressKey{}).(func()); ok {
+		progress()
+	}
+}
+
+// challenged slows down the requests of the limiter after a challenge page, and counts it.
+func (c *Client) challenged(rate *limiter) {
+	c.counters.challenges.Add(1)
+	if rate.challenged() {
+		c.counters.pauses.Add(1)
+	}
+}
+
+// Stats returns the counters of the requests so far.
+func (c *Client) Stats() Stats {
+	cs := &c.counters
+	st := Stats{Requests: int(cs.requests.Load()), CacheHits: int(cs.cacheHits.Load()),
+		Latency: time.Duration(cs.latency.Load()), Challenges: int(cs.challenges.Load()),
+		Pauses: int(cs.pauses.Load()), ImageSearches: int(cs.imageSearches.Load()),
+		CatalogSearches: int(cs.catalogSearches.Load()), Parsed: map[string]int{}}
+	for i, p := range resultParsers {
+		st.Parsed[p.name] = int(cs.parsed[i].Load())
+	}
+	return st
+}
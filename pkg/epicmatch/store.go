@@ -0,0 +1,152 @@
+package epicmatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Store is a storefront the games are matched in, the matching and the ranking of its results
+// are common. Epic is the built-in one.
+type Store interface {
+	// Search returns the results of the store search for the name in the store order. On parse
+	// errors the results found so far are returned with the error.
+	Search(ctx context.Context, name string) ([]Match, error)
+	// ProductBySlug returns the link of the product page of the slug if it exists, empty if not.
+	// The link of a page unavailable in the region is returned with ErrRegionLocked.
+	ProductBySlug(ctx context.Context, slug string) (string, error)
+	// ProductURL returns the link of the product page of the slug, without checking it.
+	ProductURL(slug string) string
+}
+
+// LocaleStore is a Store that can be searched in other locales, for localized names, see
+// Config.Locales.
+type LocaleStore interface {
+	// SearchLocale is Search in the store locale, the links of the results are in the main locale.
+	SearchLocale(ctx context.Context, locale, name string) ([]Match, error)
+}
+
+// stores are the built-in stores by name, see Config.Store.
+var stores = map[string]func(c *Client) Store{
+	"epic": func(c *Client) Store { return epicStore{c} },
+}
+
+// SetStore replaces the store of the client.
+func (c *Client) SetStore(s Store) {
+	c.store = s
+}
+
+// epicStore is the Epic Games Store, searched by scraping the browse page.
+type epicStore struct {
+	c *Client
+}
+
+func (s epicStore) Search(ctx context.Context, name string) ([]Match, error) {
+	return s.SearchLocale(ctx, s.c.cfg.Locale, name)
+}
+
+// SearchLocale gets the pages of the search results up to Config.MaxResults, or until a page with
+// a result of the same name.
+func (s epicStore) SearchLocale(ctx context.Context, locale, name string) ([]Match, error) {
+	var matches []Match
+	for start := 0; start < s.c.cfg.MaxResults; start += s.c.cfg.PageSize {
+		page, err := s.searchPage(ctx, locale, name, start)
+		if err != nil {
+			if start > 0 && errors.Is(err, ErrNoResults) {
+				break // past the last page
+			}
+			return append(matches, page...), err
+		}
+		found := false
+		for _, m := range page {
+			if !slices.ContainsFunc(matches, func(o Match) bool { return o.Link == m.Link }) {
+				matches = append(matches, m)
+			}
+			found = found || SameName(m.Name, name)
+		}
+		if found || len(page) < s.c.cfg.PageSize {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// searchPage gets a page of the search results from the start offset.
+func (s epicStore) searchPage(ctx context.Context, locale, name string, start int) ([]Match, error) {
+	c := s.c
+	link := fmt.Sprintf("%s/%s/browse?q=%s&sortBy=relevancy&sortDir=DESC&count=%d%s%s",
+		Host, locale, url.QueryEscape(name), c.cfg.PageSize, c.categoryQuery(), c.countryQuery("&"))
+	if start > 0 {
+		link += "&start=" + strconv.Itoa(start)
+	}
+
+	buf, err := c.epicGet(ctx, link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", link, err)
+	}
+	defer putBuf(buf)
+
+	doc, err := goquery.NewDocumentFromReader(buf)
+	if err != nil {
+		return nil, fmt.Errorf("search document failed for url %s: %w", link, err)
+	}
+
+	matches, _, err := searchParser{parsed: c.counters.parsed[:]}.parse(doc)
+	if locale != c.cfg.Locale {
+		for i := range matches {
+			matches[i].Link = strings.Replace(matches[i].Link, Host+"/"+locale+"/", Host+"/"+c.cfg.Locale+"/", 1)
+		}
+	}
+	if err != nil {
+		return matches, fmt.Errorf("search results of %s: %w", link, err)
+	}
+	return matches, nil
+}
+
+// ProductBySlug requests the product page, the store shows its not found page for missing ones.
+// A page unavailable in the region is tried again in Config.RegionLocale, if any.
+func (s epicStore) ProductBySlug(ctx context.Context, slug string) (string, error) {
+	link := s.ProductURL(slug)
+	buf, err := s.c.epicGet(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	defer putBuf(buf)
+	switch {
+	case regionLocked(buf.Bytes()):
+		if l := s.c.cfg.RegionLocale; len(l) > 0 && l != s.c.cfg.Locale {
+			if other, err := s.inLocale(ctx, l, slug); err == nil && len(other) > 0 {
+				return other, nil
+			}
+		}
+		return link, fmt.Errorf("%w: %s", ErrRegionLocked, link)
+	case bytes.Contains(buf.Bytes(), s.c.notFound):
+		return "", nil
+	}
+	return link, nil
+}
+
+// inLocale returns the product page of the slug in the locale, if it's available there.
+func (s epicStore) inLocale(ctx context.Context, locale, slug string) (string, error) {
+	link := fmt.Sprintf("%s/%s/p/%s", Host, locale, slug)
+	buf, err := s.c.epicGet(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	defer putBuf(buf)
+	if regionLocked(buf.Bytes()) || bytes.Contains(buf.Bytes(), []byte("/"+locale+"/not-found")) {
+		return "", nil
+	}
+	return link, nil
+}
+
+func (s epicStore) ProductURL(slug string) string {
+	return fmt.Sprintf("%s/%s/p/%s", Host, s.c.cfg.Locale, slug)
+}